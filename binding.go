@@ -0,0 +1,80 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// loxPackage is the import path of this package. Pointers to its types are Lox values, not Go structs to wrap.
+var loxPackage = reflect.TypeOf(LoxList{}).PkgPath()
+
+// BindValue defines a global variable called name that holds value, converted like the results of RegisterFunc.
+// A pointer to a struct becomes an object whose exported fields can be read and assigned,
+// and whose exported methods can be called. A struct that is not passed by pointer is copied.
+func (i *Interpreter) BindValue(name string, value interface{}) {
+	i.Globals.Define(name, fromGo(reflect.ValueOf(value)))
+}
+
+// LoxGoValue is a Go struct handed to a script. It holds a pointer to the struct, so assigning a field
+// changes the struct that the embedder sees. Unexported fields and methods are hidden.
+type LoxGoValue struct {
+	value reflect.Value
+}
+
+// newLoxGoValue wraps value, which must be a pointer to a struct.
+func newLoxGoValue(value reflect.Value) *LoxGoValue {
+	return &LoxGoValue{value}
+}
+
+func (g *LoxGoValue) ToString() string {
+	if stringer, ok := g.value.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return "<go " + g.value.Type().String() + ">"
+}
+
+// field returns the exported field called name, which may be promoted from an embedded struct.
+func (g *LoxGoValue) field(name string) (reflect.Value, bool) {
+	f, ok := g.value.Elem().Type().FieldByName(name)
+	if !ok || !f.IsExported() {
+		return reflect.Value{}, false
+	}
+
+	// a promoted field is unreachable while the embedded pointer that holds it is nil.
+	value, err := g.value.Elem().FieldByIndexErr(f.Index)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	return value, true
+}
+
+func (g *LoxGoValue) Get(name Token) (interface{}, error) {
+	if field, ok := g.field(name.Lexeme); ok {
+		return fromGo(field), nil
+	}
+
+	if method := g.value.MethodByName(name.Lexeme); method.IsValid() {
+		native, err := newGoFunction(name.Lexeme, method)
+		if err != nil {
+			return nil, NewEnvironmentError(name, fmt.Sprintf("Cannot use method '%s': %s.", name.Lexeme, err.Error()))
+		}
+		return native, nil
+	}
+
+	return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
+
+func (g *LoxGoValue) Set(name Token, value interface{}) error {
+	field, ok := g.field(name.Lexeme)
+	if !ok || !field.CanSet() {
+		return fmt.Errorf("Undefined field '%s'.", name.Lexeme)
+	}
+
+	converted, err := toGo(value, field.Type())
+	if err != nil {
+		return fmt.Errorf("Field '%s' %s.", name.Lexeme, err.Error())
+	}
+
+	field.Set(converted)
+	return nil
+}
@@ -0,0 +1,89 @@
+package lox_interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultMaxCallDepth is the call depth at which a script fails with ErrStackOverflow, unless WithMaxCallDepth
+// sets another one. It keeps runaway recursion far away from the limit of the Go stack.
+const DefaultMaxCallDepth = 10000
+
+var (
+	// ErrBudgetExceeded is the cause of the RuntimeError returned when a script evaluates more nodes than
+	// WithMaxSteps allows, or when the context given to WithContext is done.
+	ErrBudgetExceeded = errors.New("execution budget exceeded")
+	// ErrStackOverflow is the cause of the RuntimeError returned when calls nest deeper than the maximum call depth.
+	ErrStackOverflow = errors.New("stack overflow")
+)
+
+// WithContext stops the script once ctx is done. Blocking operations such as waiting for a timer,
+// a task or a channel are interrupted too.
+func WithContext(ctx context.Context) Option {
+	return func(i *Interpreter) {
+		i.budget.ctx = ctx
+	}
+}
+
+// WithMaxSteps limits the number of statements and expressions a script evaluates. Zero means no limit.
+// Spawned functions, timers and async functions count against the same budget.
+func WithMaxSteps(steps int64) Option {
+	return func(i *Interpreter) {
+		i.budget.maxSteps = steps
+	}
+}
+
+// WithMaxCallDepth limits how deep calls can nest. Zero means no limit.
+func WithMaxCallDepth(depth int) Option {
+	return func(i *Interpreter) {
+		i.budget.maxCallDepth = depth
+	}
+}
+
+// budget holds the limits of an interpreter. It is shared with every fork of the interpreter.
+type budget struct {
+	ctx          context.Context
+	maxSteps     int64
+	steps        atomic.Int64
+	maxCallDepth int
+	maxMemory    int64
+	allocated    atomic.Int64
+}
+
+func newBudget() *budget {
+	return &budget{
+		ctx:          context.Background(),
+		maxCallDepth: DefaultMaxCallDepth,
+	}
+}
+
+// step counts one evaluated node, and fails when the budget is exhausted or the context is done.
+func (i *Interpreter) step() error {
+	if steps := i.budget.steps.Add(1); i.budget.maxSteps > 0 && steps > i.budget.maxSteps {
+		return i.budgetError(Token{}, ErrBudgetExceeded)
+	}
+
+	select {
+	case <-i.budget.ctx.Done():
+		return i.interrupted(Token{})
+	default:
+		return nil
+	}
+}
+
+// interrupted returns the error for a context that is done.
+func (i *Interpreter) interrupted(token Token) error {
+	return i.budgetError(token, fmt.Errorf("%w: %w", ErrBudgetExceeded, i.budget.ctx.Err()))
+}
+
+// isBudgetError reports whether err is raised because a limit of the interpreter was reached,
+// which is not a problem at any particular place of the script.
+func isBudgetError(err error) bool {
+	return errors.Is(err, ErrBudgetExceeded) || errors.Is(err, ErrStackOverflow) || errors.Is(err, ErrOutOfMemory)
+}
+
+func (i *Interpreter) budgetError(token Token, cause error) error {
+	return &RuntimeError{token, cause.Error(), append([]Callable(nil), i.callStack...), cause}
+}
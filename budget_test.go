@@ -0,0 +1,42 @@
+package lox_interpreter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+func TestBudgetErrorsInsideNativeCallsHaveNoLocation(t *testing.T) {
+	_, err := runScript(t, `
+fun forever(x) { while (true) {} }
+[1, 2].map(forever);
+`, lox.WithMaxSteps(1000))
+	if !errors.Is(err, lox.ErrBudgetExceeded) {
+		t.Fatalf("got %v, want ErrBudgetExceeded", err)
+	}
+	if !strings.HasPrefix(err.Error(), lox.ErrBudgetExceeded.Error()) {
+		t.Errorf("got %q, want the error without a location", err.Error())
+	}
+}
+
+func TestStackOverflowTraceCollapsesRepeatedFrames(t *testing.T) {
+	_, err := runScript(t, `
+fun down(n) { return down(n + 1); }
+fun start() { return down(0); }
+start();
+`)
+	if !errors.Is(err, lox.ErrStackOverflow) {
+		t.Fatalf("got %v, want ErrStackOverflow", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	want := []string{
+		"[line 2] in <fn down> (repeated 9998 more times)",
+		"[line 3] in <fn start>",
+	}
+	if len(lines) != 3 || lines[1] != want[0] || lines[2] != want[1] {
+		t.Errorf("got trace %q, want %q after the message", lines, want)
+	}
+}
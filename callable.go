@@ -2,7 +2,7 @@ package lox_interpreter
 
 import (
 	"fmt"
-	"time"
+	"unicode/utf8"
 )
 
 type Callable interface {
@@ -12,8 +12,26 @@ type Callable interface {
 	Bind(instance *LoxInstance) Callable
 }
 
+// optionalArity is implemented by callables that can be called with fewer arguments than their Arity.
+type optionalArity interface {
+	MinArity() int
+}
+
+// arityRange returns the fewest and the most arguments callable accepts.
+func arityRange(callable Callable) (min, max int) {
+	max = callable.Arity()
+	if c, ok := callable.(optionalArity); ok {
+		return c.MinArity(), max
+	}
+
+	return max, max
+}
+
 var _ Callable = (*LoxFunction)(nil)
 
+// LoxFunction is a function declared by a script. Its declaration may be shared with other interpreters
+// that run the same syntax tree, so it is only read; the state of a call lives in the environments.
+// TestConcurrentRuns checks this with the race detector.
 type LoxFunction struct {
 	declaration   *FunStmt
 	closure       *Environment
@@ -41,6 +59,14 @@ func (f *LoxFunction) Call(interpreter *Interpreter, arguments []interface{}) (i
 		env.Define(param.Lexeme, arguments[i])
 	}
 
+	if f.declaration.isGenerator {
+		return NewLoxGenerator(f, interpreter, env), nil
+	}
+
+	if f.declaration.isAsync {
+		return interpreter.startAsync(env, f.declaration.body), nil
+	}
+
 	value, err := interpreter.executeBlock(f.declaration.body, env)
 	if err != nil {
 		return nil, err
@@ -60,6 +86,55 @@ func (f *LoxFunction) ToString() string {
 	return "<fn " + f.declaration.name.Lexeme + ">"
 }
 
+var _ Callable = (*NativeFunction)(nil)
+
+// NativeFunction is a Callable backed by a Go function. It is used for the methods of built-in values.
+type NativeFunction struct {
+	name     string
+	minArity int
+	arity    int
+	fn       func(interpreter *Interpreter, arguments []interface{}) (interface{}, error)
+}
+
+func NewNativeFunction(name string, arity int, fn func(interpreter *Interpreter, arguments []interface{}) (interface{}, error)) *NativeFunction {
+	return NewNativeFunctionWithOptional(name, arity, arity, fn)
+}
+
+// NewNativeFunctionWithOptional creates a NativeFunction whose arguments after the first minArity may be left out.
+// fn always gets arity arguments; the ones left out are nil.
+func NewNativeFunctionWithOptional(name string, minArity, arity int, fn func(interpreter *Interpreter, arguments []interface{}) (interface{}, error)) *NativeFunction {
+	return &NativeFunction{
+		name,
+		minArity,
+		arity,
+		fn,
+	}
+}
+
+func (n *NativeFunction) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	for len(arguments) < n.arity {
+		arguments = append(arguments, nil)
+	}
+
+	return n.fn(interpreter, arguments)
+}
+
+func (n *NativeFunction) Arity() int {
+	return n.arity
+}
+
+func (n *NativeFunction) MinArity() int {
+	return n.minArity
+}
+
+func (n *NativeFunction) ToString() string {
+	return "<native fn " + n.name + ">"
+}
+
+func (n *NativeFunction) Bind(instance *LoxInstance) Callable {
+	return n
+}
+
 var _ Callable = (*Clock)(nil)
 
 type Clock struct{}
@@ -69,7 +144,11 @@ func (c *Clock) ToString() string {
 }
 
 func (c *Clock) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
-	return float64(time.Now().UnixNano()), nil
+	if !interpreter.capabilities.Clock {
+		return nil, permissionDenied("cannot read the clock")
+	}
+
+	return float64(interpreter.loop.now().UnixNano()), nil
 }
 
 func (c *Clock) Arity() int {
@@ -87,11 +166,15 @@ type Len struct{}
 func (l Len) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
 	switch arg := arguments[0].(type) {
 	case string:
-		return float64(len(arg)), nil
-	case ListType:
+		return float64(utf8.RuneCountInString(arg)), nil
+	case *LoxList:
+		return float64(arg.len()), nil
+	case *LoxDict:
+		return float64(arg.len()), nil
+	case TupleType:
 		return float64(len(arg)), nil
 	default:
-		return nil, fmt.Errorf("Argument must be a string or an array.")
+		return nil, fmt.Errorf("Argument must be a string, an array or a tuple.")
 	}
 }
 
@@ -106,3 +189,39 @@ func (l Len) ToString() string {
 func (l Len) Bind(instance *LoxInstance) Callable {
 	return l
 }
+
+var _ Callable = (*Freeze)(nil)
+
+// Freeze makes the given value read-only and returns it.
+// Tuples and primitive values are immutable, so they are returned as they are.
+type Freeze struct{}
+
+func (f Freeze) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	switch arg := arguments[0].(type) {
+	case *LoxInstance:
+		arg.freeze()
+		return arg, nil
+	case *LoxList:
+		arg.freeze()
+		return arg, nil
+	case *LoxDict:
+		arg.freeze()
+		return arg, nil
+	case TupleType, string, float64, bool, nil:
+		return arg, nil
+	default:
+		return nil, fmt.Errorf("Only instances, lists and dictionaries can be frozen.")
+	}
+}
+
+func (f Freeze) Arity() int {
+	return 1
+}
+
+func (f Freeze) ToString() string {
+	return "<native fn freeze>"
+}
+
+func (f Freeze) Bind(instance *LoxInstance) Callable {
+	return f
+}
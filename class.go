@@ -1,6 +1,9 @@
 package lox_interpreter
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 var _ Callable = (*LoxClass)(nil)
 
@@ -19,6 +22,10 @@ func NewLoxClass(name string, superclass *LoxClass, methods map[string]Callable)
 }
 
 func (l *LoxClass) Call(interpreter *Interpreter, arguments []interface{}) (_ interface{}, err error) {
+	if err := interpreter.allocate(Token{}, instanceSize); err != nil {
+		return nil, err
+	}
+
 	instance := NewLoxInstance(l)
 	init := l.findMethod("init")
 	if init != nil {
@@ -28,6 +35,7 @@ func (l *LoxClass) Call(interpreter *Interpreter, arguments []interface{}) (_ in
 	return instance, err
 }
 
+// Arity returns the arity of the nearest `init`, which may be inherited from a superclass.
 func (l *LoxClass) Arity() int {
 	if init := l.findMethod("init"); init != nil {
 		return init.Arity()
@@ -36,9 +44,12 @@ func (l *LoxClass) Arity() int {
 	return 0
 }
 
+// findMethod looks up the method on this class first and then walks up the superclass chain.
 func (l *LoxClass) findMethod(name string) Callable {
-	if method, ok := l.methods[name]; ok {
-		return method
+	for class := l; class != nil; class = class.superclass {
+		if method, ok := class.methods[name]; ok {
+			return method
+		}
 	}
 
 	return nil
@@ -55,21 +66,40 @@ func (l *LoxClass) Bind(instance *LoxInstance) Callable {
 type LoxInstance struct {
 	class  *LoxClass
 	fields map[string]interface{}
+	frozen bool
+	mu     sync.RWMutex
 }
 
 func NewLoxInstance(class *LoxClass) *LoxInstance {
 	return &LoxInstance{
-		class,
-		make(map[string]interface{}),
+		class:  class,
+		fields: make(map[string]interface{}),
 	}
 }
 
+// ToString does not call the toString method of the class. The interpreter calls it when it prints the instance.
 func (l *LoxInstance) ToString() string {
 	return fmt.Sprintf("<inst %s>", l.class.name)
 }
 
+// snapshot returns a copy of the fields, which callers can use without holding the lock.
+func (l *LoxInstance) snapshot() map[string]interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	fields := make(map[string]interface{}, len(l.fields))
+	for name, value := range l.fields {
+		fields[name] = value
+	}
+	return fields
+}
+
 func (l *LoxInstance) Get(name Token) (interface{}, error) {
-	if value, ok := l.fields[name.Lexeme]; ok {
+	l.mu.RLock()
+	value, ok := l.fields[name.Lexeme]
+	l.mu.RUnlock()
+
+	if ok {
 		if _, ok := value.(*LiteralExpr); ok {
 			return value.(*LiteralExpr).value, nil
 		} else if _, ok := value.(*VariableExpr); ok {
@@ -82,14 +112,64 @@ func (l *LoxInstance) Get(name Token) (interface{}, error) {
 		return method.Bind(l), nil
 	}
 
-	if l.class.superclass != nil {
-		return l.class.superclass.findMethod(name.Lexeme), nil
-	}
-
 	return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
 }
 
+func (l *LoxInstance) has(field string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	_, ok := l.fields[field]
+	return ok
+}
+
 func (l *LoxInstance) Set(name Token, value interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.frozen {
+		return fmt.Errorf("Cannot set property '%s' on a frozen instance of %s.", name.Lexeme, l.class.name)
+	}
+
 	l.fields[name.Lexeme] = value
 	return nil
 }
+
+func (l *LoxInstance) freeze() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.frozen = true
+}
+
+// LoxTrait is a named set of methods that is copied into every class that mixes it in with `with`.
+type LoxTrait struct {
+	name    string
+	methods []*FunStmt
+	closure *Environment
+}
+
+func NewLoxTrait(name string, methods []*FunStmt, closure *Environment) *LoxTrait {
+	return &LoxTrait{
+		name,
+		methods,
+		closure,
+	}
+}
+
+func (t *LoxTrait) ToString() string {
+	return fmt.Sprintf("<trait %s>", t.name)
+}
+
+// bindTo creates the trait's methods for the given class. `super` inside them refers to the superclass of that class.
+func (t *LoxTrait) bindTo(superclass *LoxClass) map[string]Callable {
+	env := NewEnvironment(t.closure)
+	env.Define("super", superclass)
+
+	methods := make(map[string]Callable)
+	for _, method := range t.methods {
+		methods[method.name.Lexeme] = NewFunction(method, env, method.name.Lexeme == "init")
+	}
+
+	return methods
+}
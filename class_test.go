@@ -0,0 +1,48 @@
+package lox_interpreter_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+func TestToStringInsideContainers(t *testing.T) {
+	got, err := runScript(t, `
+class P {
+  init(x) { this.x = x; }
+  toString() { return "P" + inspect(this.x); }
+}
+print [P(1), {k: P(2)}, (P(3), 0)];
+print "a" + P(4);
+print [P(5)].join(", ");
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[P1, {k: P2}, (P3, 0)]\naP4\nP5\n"
+	if got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+}
+
+func TestToStringErrors(t *testing.T) {
+	for _, source := range []string{
+		"class Bad { toString() { return 1; } }\nprint Bad();",
+		"class Bad { toString() { return 1; } }\nprint [Bad()];",
+		"class Bad { toString() { return 1; } }\nprint {k: (Bad(), 1)};",
+	} {
+		_, err := runScript(t, source)
+
+		var runtimeError *lox.RuntimeError
+		if !errors.As(err, &runtimeError) {
+			t.Errorf("%s: got %v, want a RuntimeError", source, err)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), "2 at 'print' Method toString must return a string.") {
+			t.Errorf("%s: got %q", source, err.Error())
+		}
+	}
+}
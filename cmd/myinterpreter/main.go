@@ -17,37 +17,107 @@ var commandMap = map[string]bool{
 }
 
 var UseCrossAdd = false
+var Strict = false
+var IntegerDivision = false
+
+// rootsFlag collects directories from a flag that can be repeated or hold a comma separated list.
+type rootsFlag []string
+
+func (r *rootsFlag) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *rootsFlag) Set(value string) error {
+	*r = append(*r, strings.Split(value, ",")...)
+	return nil
+}
+
+// capabilities of scripts started with the run command.
+var (
+	runFlags    = flag.NewFlagSet("run", flag.ExitOnError)
+	allowRead   rootsFlag
+	allowWrite  rootsFlag
+	allowExec   = runFlags.Bool("allow-exec", false, "Allow running commands")
+	allowEnv    = runFlags.Bool("allow-env", false, "Allow reading environment variables")
+	allowClock  = runFlags.Bool("allow-clock", true, "Allow reading the clock")
+	allowRandom = runFlags.Bool("allow-random", false, "Allow random numbers")
+	allowAll    = runFlags.Bool("allow-all", false, "Allow everything")
+)
 
 func init() {
 	log.SetFlags(log.Lmsgprefix)
 
 	flag.BoolVar(&UseCrossAdd, "cross-add", false, "Use cross-addition instead of regular addition")
+	flag.BoolVar(&Strict, "strict", false, "Require booleans in conditions")
+	flag.BoolVar(&IntegerDivision, "integer-division", false, "Drop the fraction when dividing integers")
+
+	runFlags.Var(&allowRead, "allow-read", "Allow reading files under these directories")
+	runFlags.Var(&allowWrite, "allow-write", "Allow writing files under these directories")
+}
+
+func capabilities() lox.Capabilities {
+	if *allowAll {
+		return lox.Capabilities{
+			ReadRoots:  []string{"/"},
+			WriteRoots: []string{"/"},
+			Exec:       true,
+			Env:        true,
+			Clock:      true,
+			Random:     true,
+		}
+	}
+
+	return lox.Capabilities{
+		ReadRoots:  allowRead,
+		WriteRoots: allowWrite,
+		Exec:       *allowExec,
+		Env:        *allowEnv,
+		Clock:      *allowClock,
+		Random:     *allowRandom,
+	}
+}
+
+func config() lox.Config {
+	return lox.Config{
+		CrossAddition:   UseCrossAdd,
+		Strict:          Strict,
+		IntegerDivision: IntegerDivision,
+	}
 }
 
 func main() {
 	flag.Parse()
-	if UseCrossAdd {
-		lox.UseCrossAddition()
-	}
 
 	// You can use print statements as follows for debugging, they'll be visible when running tests.
 	fmt.Fprintln(os.Stderr, "Logs from your program will appear here!")
 
-	if len(os.Args) < 3 {
+	if flag.NArg() < 2 {
 		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh tokenize <filename>")
+		fmt.Fprintln(os.Stderr, "       ./your_program.sh run [--allow-read=<dirs>] [--allow-write=<dirs>] [--allow-exec] [--allow-env] [--allow-random] [--allow-all] <filename>")
 		os.Exit(1)
 	}
 
-	command := os.Args[1]
+	command := flag.Arg(0)
 
 	if _, ok := commandMap[command]; !ok {
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
 		os.Exit(1)
 	}
 
+	arguments := flag.Args()[1:]
+	if command == "run" {
+		runFlags.Parse(arguments)
+		arguments = runFlags.Args()
+	}
+
+	if len(arguments) < 1 {
+		fmt.Fprintln(os.Stderr, "Missing filename.")
+		os.Exit(1)
+	}
+
 	// Uncomment this block to pass the first stage
 	//
-	filename := os.Args[2]
+	filename := arguments[0]
 	fileContents, err := os.ReadFile(filename)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
@@ -130,7 +200,7 @@ func parse(scanner *lox.Scanner) (err error) {
 		return
 	}
 
-	parser := lox.NewParser(tokens)
+	parser := lox.NewParser(tokens, config())
 	expression, err := parser.Expression()
 
 	if err != nil {
@@ -153,13 +223,13 @@ func evaluate(scanner *lox.Scanner) (err error) {
 		return
 	}
 
-	parser := lox.NewParser(tokens)
+	parser := lox.NewParser(tokens, config())
 	expression, err := parser.Expression()
 	if err != nil {
 		return
 	}
 
-	interpreter := lox.NewInterpreter(nil)
+	interpreter := lox.NewInterpreter(nil, lox.WithConfig(config()))
 	v, err := interpreter.Evaluate(expression)
 
 	if err != nil {
@@ -177,14 +247,14 @@ func run(scanner *lox.Scanner) (err error) {
 		return
 	}
 
-	parser := lox.NewParser(tokens)
+	parser := lox.NewParser(tokens, config())
 	statements, err := parser.Parse()
 
 	if err != nil {
 		return
 	}
 
-	interpreter := lox.NewInterpreter(nil)
+	interpreter := lox.NewInterpreter(nil, lox.WithCapabilities(capabilities()), lox.WithConfig(config()))
 
 	resolver := lox.NewResolver(interpreter)
 	err = resolver.Resolve(statements...)
@@ -36,7 +36,7 @@ func main() {
 				continue
 			}
 
-			parser := lox.NewParser(singleLineTokens)
+			parser := lox.NewParser(singleLineTokens, lox.Config{})
 			statements, err := parser.Parse()
 			if err != nil {
 				fmt.Println(err)
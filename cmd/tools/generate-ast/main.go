@@ -29,9 +29,14 @@ func main() {
 		"Variable   : Token name",
 		"This       : Token keyword",
 		"Super      : Token keyword, Token method",
-		"Dictionary : map[Token]Expr mapExpr",
+		"Dictionary : Token brace, []Expr keys, []Expr values",
 		"Select     : Expr object, Expr name",
 		"List       : []Expr values",
+		"Optional   : Expr object, Token operator",
+		"Chain      : Expr expression",
+		"Spawn      : Token keyword, *CallExpr call",
+		"Await      : Token keyword, Expr value",
+		"Tuple      : Token paren, []Expr values",
 	})
 	if err != nil {
 		panic(err)
@@ -39,15 +44,25 @@ func main() {
 
 	err = defineAst(outputDir, "Stmt", []string{
 		"Var        : Token name, Expr initializer",
-		"Fun        : Token name, []Token params, []Stmt body",
+		"Const      : Token name, Expr initializer",
+		"Unpack     : []Token names, Token equals, Expr initializer",
+		"Fun        : Token name, []Token params, []Stmt body, bool isGenerator, bool isAsync",
 		"Expression : Expr expression",
 		"If         : Expr condition, Stmt thenBranch, Stmt elseBranch",
-		"Print      : Expr expression",
+		"Print      : Token keyword, Expr expression",
 		"While      : Expr condition, Stmt body",
+		"ForIn      : Token name, Expr iterable, Stmt body",
 		"Break      : Token keyword",
 		"Return     : Token keyword, Expr value",
+		"Yield      : Token keyword, Expr value",
 		"Block      : []Stmt statements",
-		"Class      : Token name, *VariableExpr superClass, []*FunStmt methods",
+		"Class      : Token name, *VariableExpr superClass, []*VariableExpr traits, []*FunStmt methods",
+		"Trait      : Token name, []*FunStmt methods",
+		"Select     : Token keyword, []*CaseStmt cases, Stmt defaultBranch",
+		"Case       : Token keyword, Token name, Expr channel, Token operation, Expr value, Stmt body",
+		"Enum       : Token name, []Token variants, [][]Token fields",
+		"Match      : Token keyword, Expr value, []*MatchArmStmt arms, Stmt defaultBranch",
+		"MatchArm   : Token keyword, Expr pattern, Token variant, []Token bindings, Stmt body",
 	})
 	if err != nil {
 		panic(err)
@@ -77,10 +92,13 @@ func defineAst(outputDir string, baseName string, types []string) (err error) {
 	}
 	fmt.Fprintln(f, "}")
 
-	fmt.Fprintf(f, `type %s interface {
+	fmt.Fprintf(f, `// %s is a node of the syntax tree. Nodes are never changed after parsing: the resolver and the interpreter
+// keep their state in their own structures, so one tree can be run by interpreters on several goroutines at once.
+// TestConcurrentRuns checks this with the race detector.
+type %s interface {
 	Accept(v %sVisitor) (interface{}, error)
 }
-`, baseName, baseName)
+`, baseName, baseName, baseName)
 
 	for _, t := range types {
 		tokens := strings.Split(t, ":")
@@ -119,7 +137,7 @@ func defineType(f *os.File, baseName string, className string, fieldList string)
 	for _, field := range fields {
 		fmt.Fprintf(f, "	%s\n", field)
 	}
-	fmt.Fprintln(f, "}\n")
+	fmt.Fprint(f, "}\n\n")
 
 	fmt.Fprintf(f, "func New%s(%s) *%s {\n", strings.ToUpper(classNameWithBaseName[:1])+classNameWithBaseName[1:], fieldList, classNameWithBaseName)
 	fmt.Fprintf(f, "	return &%s{\n", classNameWithBaseName)
@@ -129,9 +147,9 @@ func defineType(f *os.File, baseName string, className string, fieldList string)
 	}
 	fmt.Fprintln(f, "	}")
 
-	fmt.Fprintln(f, "}\n")
+	fmt.Fprint(f, "}\n\n")
 
 	fmt.Fprintf(f, "func (e *%s) Accept(v %sVisitor) (interface{}, error) {\n", classNameWithBaseName, baseName)
 	fmt.Fprintf(f, "	return v.Visit%s(e)\n", classNameWithBaseName)
-	fmt.Fprintln(f, "}\n")
+	fmt.Fprint(f, "}\n\n")
 }
@@ -0,0 +1,238 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// LoxTask is the result of `spawn f(x)`. `await task` blocks until the function has returned.
+type LoxTask struct {
+	name  string
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func NewLoxTask(name string) *LoxTask {
+	return &LoxTask{
+		name: name,
+		done: make(chan struct{}),
+	}
+}
+
+func (t *LoxTask) ToString() string {
+	return "<task " + t.name + ">"
+}
+
+func (t *LoxTask) complete(value interface{}, err error) {
+	t.value = value
+	t.err = err
+	close(t.done)
+}
+
+// Wait blocks until the spawned function has returned and returns its result.
+func (t *LoxTask) Wait() (interface{}, error) {
+	<-t.done
+	return t.value, t.err
+}
+
+// deadlockInterval is how long every task of a script must have been waiting before that counts as a deadlock.
+const deadlockInterval = 50 * time.Millisecond
+
+// tasks counts the tasks of a script, which are the main one and the spawned ones, and how many of them wait
+// on channels or other tasks. Goroutines of generators and async functions belong to the task that resumes them.
+type tasks struct {
+	mu       sync.Mutex
+	running  int
+	waiting  int
+	progress uint64
+	// stalled is the progress at the time since which every task has been waiting.
+	stalled  uint64
+	since    time.Time
+	deadlock chan struct{}
+}
+
+func newTasks() *tasks {
+	return &tasks{
+		running:  1,
+		deadlock: make(chan struct{}),
+	}
+}
+
+func (t *tasks) update(running, waiting int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.running += running
+	t.waiting += waiting
+	t.progress++
+}
+
+// wait counts a task as waiting, and returns the channel that is closed when a deadlock is found.
+func (t *tasks) wait() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.waiting++
+	t.progress++
+	return t.deadlock
+}
+
+// check reports a deadlock to the waiting tasks once all of them have been waiting, without any task
+// starting, finishing or being woken, for a deadlockInterval.
+// Go pairs a waiting sender with a waiting receiver at once, so such tasks would never wake each other.
+func (t *tasks) check() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.waiting < t.running {
+		t.since = time.Time{}
+		return
+	}
+
+	if t.since.IsZero() || t.stalled != t.progress {
+		t.since = time.Now()
+		t.stalled = t.progress
+		return
+	}
+
+	if time.Since(t.since) >= deadlockInterval {
+		close(t.deadlock)
+		t.deadlock = make(chan struct{})
+		t.since = time.Time{}
+	}
+}
+
+// wait blocks until one of cases can go ahead, like reflect.Select, and counts the task as waiting meanwhile.
+// It fails when the context of the interpreter is done, or when every task of the script is waiting,
+// so that a script that waits for itself ends with a runtime error instead of hanging or crashing the host.
+func (i *Interpreter) wait(token Token, cases []reflect.SelectCase) (chosen int, received reflect.Value, ok bool, err error) {
+	n := len(cases)
+
+	// an operation that can go ahead at once does not wait.
+	chosen, received, ok = reflect.Select(append(cases[:n:n], reflect.SelectCase{Dir: reflect.SelectDefault}))
+	if chosen < n {
+		return chosen, received, ok, nil
+	}
+
+	deadlock := i.tasks.wait()
+	defer i.tasks.update(0, -1)
+
+	ticker := time.NewTicker(deadlockInterval / 2)
+	defer ticker.Stop()
+
+	cases = append(cases[:n:n],
+		reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(i.budget.ctx.Done())},
+		reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(deadlock)},
+		reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ticker.C)},
+	)
+	for {
+		chosen, received, ok = reflect.Select(cases)
+		switch chosen {
+		case n:
+			return chosen, received, false, i.interrupted(token)
+		case n + 1:
+			return chosen, received, false, NewRuntimeError(token, "Deadlock: every task is waiting.", i.callStack)
+		case n + 2:
+			i.tasks.check()
+			continue
+		}
+
+		return chosen, received, ok, nil
+	}
+}
+
+// LoxChannel is created by the `channel()` native. Receiving from a closed channel gives nil.
+type LoxChannel struct {
+	ch     chan interface{}
+	mu     sync.Mutex
+	closed bool
+}
+
+func NewLoxChannel() *LoxChannel {
+	return &LoxChannel{
+		ch: make(chan interface{}),
+	}
+}
+
+func (c *LoxChannel) ToString() string {
+	return "<channel>"
+}
+
+// Send blocks until the value is received, the context of the interpreter is done, or every task is waiting.
+func (c *LoxChannel) Send(interpreter *Interpreter, token Token, value interface{}) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = fmt.Errorf("Cannot send on a closed channel.")
+		}
+	}()
+
+	_, _, _, err = interpreter.wait(token, []reflect.SelectCase{{Dir: reflect.SelectSend, Chan: reflect.ValueOf(c.ch), Send: reflect.ValueOf(&value).Elem()}})
+	return err
+}
+
+// Receive blocks until a value is sent, the context of the interpreter is done, or every task is waiting.
+// ok is false when the channel is closed.
+func (c *LoxChannel) Receive(interpreter *Interpreter, token Token) (value interface{}, ok bool, err error) {
+	_, received, ok, err := interpreter.wait(token, []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(c.ch)}})
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	return received.Interface(), true, nil
+}
+
+func (c *LoxChannel) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return fmt.Errorf("Channel is already closed.")
+	}
+
+	c.closed = true
+	close(c.ch)
+	return nil
+}
+
+func (c *LoxChannel) Get(name Token) (interface{}, error) {
+	switch name.Lexeme {
+	case "send":
+		return NewNativeFunction("send", 1, func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+			return nil, c.Send(interpreter, name, arguments[0])
+		}), nil
+	case "receive":
+		return NewNativeFunction("receive", 0, func(interpreter *Interpreter, _ []interface{}) (interface{}, error) {
+			value, _, err := c.Receive(interpreter, name)
+			return value, err
+		}), nil
+	case "close":
+		return NewNativeFunction("close", 0, func(_ *Interpreter, _ []interface{}) (interface{}, error) {
+			return nil, c.Close()
+		}), nil
+	}
+
+	return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
+
+var _ Callable = (*Channel)(nil)
+
+type Channel struct{}
+
+func (c Channel) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	return NewLoxChannel(), nil
+}
+
+func (c Channel) Arity() int {
+	return 0
+}
+
+func (c Channel) ToString() string {
+	return "<native fn channel>"
+}
+
+func (c Channel) Bind(instance *LoxInstance) Callable {
+	return c
+}
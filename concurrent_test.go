@@ -0,0 +1,143 @@
+package lox_interpreter_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+const script = `
+class Shape {
+  init(name) { this.name = name; }
+  describe() { return this.name + " with area " + inspect(this.area()); }
+}
+trait Scaled {
+  scale(k) { this.size = this.size * k; return this; }
+}
+class Square < Shape with Scaled {
+  init(size) { super.init("square"); this.size = size; }
+  area() { return this.size * this.size; }
+  __add__(other) { return Square(this.size + other.size); }
+  __eq__(other) { return this.size == other.size; }
+}
+print Square(2).describe();
+print (Square(1) + Square(2)).scale(2).area();
+print Square(3) == Square(3);
+
+enum Result { Ok(value), Err(message) }
+fun check(n) {
+  if (n == 2) return Result.Ok(n);
+  return Result.Err("odd " + inspect(n));
+}
+for (var n in [1, 2, 3]) {
+  match (check(n)) {
+    case Result.Ok(v) { print "ok " + inspect(v); }
+    case Result.Err(m) { print m; }
+  }
+}
+
+fun counter() {
+  var count = 0;
+  fun increment() { count = count + 1; return count; }
+  return increment;
+}
+var next = counter();
+next();
+print next();
+
+fun range(n) { var i = 0; while (i < n) { yield i; i = i + 1; } }
+var squares = [];
+for (var i in range(5)) squares.push(i * i);
+fun addOne(x) { return x + 1; }
+fun isBig(x) { return x > 2; }
+print squares.map(addOne).filter(isBig);
+
+var q, r = (17, 5);
+var totals = {q: q, r: r};
+totals.merge({sum: q + r});
+print totals;
+print "Hello, World".lower().split(", ");
+
+fun fib(n) { if (n < 2) return n; return fib(n - 1) + fib(n - 2); }
+var task = spawn fib(15);
+async fun twice(x) { return x * 2; }
+async fun work() { return await twice(21); }
+print await task;
+print await work();
+
+var jobs = channel();
+fun produce() { for (var i in range(3)) jobs.send(i); jobs.close(); }
+spawn produce();
+var sum = 0;
+for (var job in jobs) sum = sum + job;
+print sum;
+
+fun late() { print "timer"; }
+setTimeout(late, 1);
+`
+
+const runs = 16
+
+// runStatements resolves and runs statements on a new interpreter, like cmd/myinterpreter does.
+func runStatements(statements []lox.Stmt) (string, error) {
+	var out bytes.Buffer
+	interpreter := lox.NewInterpreter(nil, lox.WithStdout(&out))
+	if err := lox.NewResolver(interpreter).Resolve(statements...); err != nil {
+		return "", err
+	}
+
+	_, err := interpreter.Interpret(statements)
+	return out.String(), err
+}
+
+// runProgram runs a compiled program on a new interpreter.
+func runProgram(program *lox.Program) (string, error) {
+	var out bytes.Buffer
+	_, err := lox.NewInterpreter(nil, lox.WithStdout(&out)).Run(program)
+	return out.String(), err
+}
+
+// TestConcurrentRuns runs one parsed script and one compiled program on many goroutines at once, each with its own
+// interpreter, and checks that every run prints the same as a run on its own.
+// Under `go test -race` it checks that running a syntax tree does not change it.
+func TestConcurrentRuns(t *testing.T) {
+	tokens, err := lox.NewScanner(script).ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statements, err := lox.NewParser(tokens, lox.Config{}).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	program, diagnostics := lox.Compile(script, lox.Config{})
+	if diagnostics != nil {
+		t.Fatal(diagnostics)
+	}
+
+	expected, err := runStatements(statements)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < runs; n++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if output, err := runStatements(statements); err != nil || output != expected {
+				t.Errorf("statements: %v\n%s", err, output)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if output, err := runProgram(program); err != nil || output != expected {
+				t.Errorf("program: %v\n%s", err, output)
+			}
+		}()
+	}
+	wg.Wait()
+}
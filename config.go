@@ -1,7 +1,21 @@
 package lox_interpreter
 
-var useCrossAddition = false
+// Config holds the switches of the language. Its zero value is the standard semantics.
+// The parser, the resolver and the interpreter of one script must use the same Config.
+type Config struct {
+	// CrossAddition makes `+` of a number and a string concatenate them, like `1 + "a"` is "1a".
+	CrossAddition bool
+	// ReturnAtRoot allows `return` outside of functions, which ends the script.
+	ReturnAtRoot bool
+	// IntegerDivision makes `/` of two integers drop the fraction, like `7 / 2` is 3.
+	IntegerDivision bool
+	// Strict makes conditions of if, while, for, `?:`, `!`, `and` and `or` require booleans instead of truthy values.
+	Strict bool
+}
 
-func UseCrossAddition() {
-	useCrossAddition = true
+// WithConfig sets the switches of the language.
+func WithConfig(config Config) Option {
+	return func(i *Interpreter) {
+		i.config = config
+	}
 }
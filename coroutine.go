@@ -0,0 +1,188 @@
+package lox_interpreter
+
+import (
+	"errors"
+	"sync"
+)
+
+// errCoroutineClosed unwinds the body of a coroutine that was closed while it was suspended.
+var errCoroutineClosed = errors.New("coroutine closed")
+
+// coroutineResult is what the body of a coroutine hands back to its caller when it suspends or finishes.
+type coroutineResult struct {
+	value interface{}
+	err   error
+	done  bool
+}
+
+type resumeValue struct {
+	value interface{}
+	err   error
+}
+
+// coroutines holds the coroutines of a script whose goroutine has started and not exited yet,
+// so that Interpret can close the ones that the script left suspended.
+type coroutines struct {
+	mu      sync.Mutex
+	running map[*coroutine]struct{}
+}
+
+func newCoroutines() *coroutines {
+	return &coroutines{
+		running: make(map[*coroutine]struct{}),
+	}
+}
+
+func (c *coroutines) add(coroutine *coroutine) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running[coroutine] = struct{}{}
+}
+
+func (c *coroutines) remove(coroutine *coroutine) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.running, coroutine)
+}
+
+// closeAll closes every coroutine that is still running, so that their goroutines exit.
+func (c *coroutines) closeAll() {
+	c.mu.Lock()
+	running := make([]*coroutine, 0, len(c.running))
+	for coroutine := range c.running {
+		running = append(running, coroutine)
+	}
+	c.mu.Unlock()
+
+	for _, coroutine := range running {
+		coroutine.shut()
+	}
+}
+
+// coroutine runs a function body on its own goroutine with its own interpreter.
+// Control is handed back and forth through channels, so only one side runs at a time.
+// It backs both generators, which suspend on `yield`, and async functions, which suspend on `await`.
+// Its goroutine exits when the body finishes, when the coroutine is closed, or when the context of the interpreter is done.
+// Interpret closes the coroutines that are still suspended when it returns.
+type coroutine struct {
+	interpreter *Interpreter
+	body        []Stmt
+	env         *Environment
+	isAsync     bool
+	started     bool
+	finished    bool
+	resume      chan resumeValue
+	results     chan coroutineResult
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+func newCoroutine(interpreter *Interpreter, body []Stmt, env *Environment, isAsync bool) *coroutine {
+	c := &coroutine{
+		body:    body,
+		env:     env,
+		isAsync: isAsync,
+		resume:  make(chan resumeValue),
+		results: make(chan coroutineResult),
+		closed:  make(chan struct{}),
+	}
+	c.interpreter = interpreter.fork(c)
+
+	return c
+}
+
+// next resumes the body with the given value and runs it until it suspends again or finishes.
+func (c *coroutine) next(value interface{}, err error) coroutineResult {
+	if c.finished {
+		return coroutineResult{done: true}
+	}
+
+	if !c.started {
+		c.started = true
+		c.interpreter.coroutines.add(c)
+		go c.run()
+	}
+
+	ctx := c.interpreter.budget.ctx
+	select {
+	case c.resume <- resumeValue{value, err}:
+	case <-c.closed:
+		c.finished = true
+		return coroutineResult{done: true}
+	case <-ctx.Done():
+		c.finished = true
+		return coroutineResult{err: c.interpreter.interrupted(Token{}), done: true}
+	}
+
+	select {
+	case result := <-c.results:
+		if result.done {
+			c.finished = true
+		}
+		return result
+	case <-c.closed:
+		c.finished = true
+		return coroutineResult{done: true}
+	case <-ctx.Done():
+		c.finished = true
+		return coroutineResult{err: c.interpreter.interrupted(Token{}), done: true}
+	}
+}
+
+// close abandons a coroutine that has not finished. A suspended body fails with errCoroutineClosed, so that it unwinds
+// and its goroutine exits.
+func (c *coroutine) close() {
+	c.finished = true
+	c.shut()
+}
+
+// shut closes the coroutine without touching the state of its caller, so that it can be called from any goroutine.
+// The caller sees the coroutine as finished when it resumes it next.
+func (c *coroutine) shut() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+}
+
+func (c *coroutine) run() {
+	defer c.interpreter.coroutines.remove(c)
+
+	ctx := c.interpreter.budget.ctx
+	select {
+	case <-c.resume:
+	case <-c.closed:
+		return
+	case <-ctx.Done():
+		return
+	}
+
+	value, err := c.interpreter.executeBlock(c.body, c.env)
+	select {
+	case c.results <- coroutineResult{value: value, err: err, done: true}:
+	case <-c.closed:
+	case <-ctx.Done():
+	}
+}
+
+// suspend is called from the body. It hands the value to the caller and blocks until the caller resumes it.
+func (c *coroutine) suspend(value interface{}) (interface{}, error) {
+	ctx := c.interpreter.budget.ctx
+	select {
+	case c.results <- coroutineResult{value: value}:
+	case <-c.closed:
+		return nil, errCoroutineClosed
+	case <-ctx.Done():
+		return nil, c.interpreter.interrupted(Token{})
+	}
+
+	select {
+	case resumed := <-c.resume:
+		return resumed.value, resumed.err
+	case <-c.closed:
+		return nil, errCoroutineClosed
+	case <-ctx.Done():
+		return nil, c.interpreter.interrupted(Token{})
+	}
+}
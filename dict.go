@@ -0,0 +1,228 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"sync"
+)
+
+// LoxDict is the value of a dictionary literal such as `{a: 1, 2: "b"}`.
+// Keys are hashed by value and iterated in the order they were inserted.
+type LoxDict struct {
+	keys   []interface{}
+	values []interface{}
+	index  map[interface{}]int
+	frozen bool
+	mu     sync.RWMutex
+}
+
+func NewLoxDict() *LoxDict {
+	return &LoxDict{
+		index: make(map[interface{}]int),
+	}
+}
+
+func (d *LoxDict) ToString() string {
+	return Stringify(d)
+}
+
+// dictKey returns the hash key of key, which must be a number, a boolean, a string or a tuple of them.
+func dictKey(key interface{}) (interface{}, error) {
+	switch key := key.(type) {
+	case float64, bool, string:
+		return key, nil
+	case TupleType:
+		for _, element := range key {
+			if _, err := dictKey(element); err != nil {
+				return nil, err
+			}
+		}
+		return hashKey(key), nil
+	}
+
+	return nil, fmt.Errorf("Dictionary keys must be numbers, booleans, strings or tuples of them.")
+}
+
+// entries returns copies of the keys and the values, which callers can use without holding the lock.
+func (d *LoxDict) entries() (keys, values []interface{}) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	keys = make([]interface{}, len(d.keys))
+	copy(keys, d.keys)
+	values = make([]interface{}, len(d.values))
+	copy(values, d.values)
+	return keys, values
+}
+
+func (d *LoxDict) len() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return len(d.keys)
+}
+
+func (d *LoxDict) Get(key interface{}) (value interface{}, ok bool, err error) {
+	hash, err := dictKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	i, ok := d.index[hash]
+	if !ok {
+		return nil, false, nil
+	}
+	return d.values[i], true, nil
+}
+
+func (d *LoxDict) Set(key, value interface{}) error {
+	hash, err := dictKey(key)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.frozen {
+		return fmt.Errorf("Cannot modify a frozen dictionary.")
+	}
+
+	d.set(hash, key, value)
+	return nil
+}
+
+// set stores value under key, whose hash key is hash. The caller must hold the lock.
+func (d *LoxDict) set(hash, key, value interface{}) {
+	if i, ok := d.index[hash]; ok {
+		d.values[i] = value
+		return
+	}
+
+	d.index[hash] = len(d.keys)
+	d.keys = append(d.keys, key)
+	d.values = append(d.values, value)
+}
+
+// Delete removes key, and reports whether it was in the dictionary.
+func (d *LoxDict) Delete(key interface{}) (bool, error) {
+	hash, err := dictKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.frozen {
+		return false, fmt.Errorf("Cannot modify a frozen dictionary.")
+	}
+
+	i, ok := d.index[hash]
+	if !ok {
+		return false, nil
+	}
+
+	d.keys = append(d.keys[:i], d.keys[i+1:]...)
+	d.values = append(d.values[:i], d.values[i+1:]...)
+	delete(d.index, hash)
+	for hash, j := range d.index {
+		if j > i {
+			d.index[hash] = j - 1
+		}
+	}
+	return true, nil
+}
+
+func (d *LoxDict) freeze() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.frozen = true
+}
+
+// dictMethod is a native method of dictionaries. Arguments after the first minArity may be left out, and are nil then.
+// Methods that create values or grow the receiver account for the memory they allocate.
+type dictMethod struct {
+	minArity int
+	arity    int
+	fn       func(interpreter *Interpreter, receiver *LoxDict, arguments []interface{}) (interface{}, error)
+}
+
+var dictMethods = map[string]dictMethod{
+	"keys": {0, 0, func(interpreter *Interpreter, receiver *LoxDict, _ []interface{}) (interface{}, error) {
+		keys, _ := receiver.entries()
+		return interpreter.account(Token{}, NewLoxList(keys))
+	}},
+	"values": {0, 0, func(interpreter *Interpreter, receiver *LoxDict, _ []interface{}) (interface{}, error) {
+		_, values := receiver.entries()
+		return interpreter.account(Token{}, NewLoxList(values))
+	}},
+	// items returns a list of (key, value) tuples.
+	"items": {0, 0, func(interpreter *Interpreter, receiver *LoxDict, _ []interface{}) (interface{}, error) {
+		keys, values := receiver.entries()
+		items := make([]interface{}, len(keys))
+		for i := range keys {
+			items[i] = TupleType{keys[i], values[i]}
+			if err := interpreter.allocate(Token{}, sizeOf(items[i])); err != nil {
+				return nil, err
+			}
+		}
+		return interpreter.account(Token{}, NewLoxList(items))
+	}},
+	"has": {1, 1, func(_ *Interpreter, receiver *LoxDict, arguments []interface{}) (interface{}, error) {
+		_, ok, err := receiver.Get(arguments[0])
+		return ok, err
+	}},
+	"delete": {1, 1, func(_ *Interpreter, receiver *LoxDict, arguments []interface{}) (interface{}, error) {
+		return receiver.Delete(arguments[0])
+	}},
+	// get returns the value of the key, or the default value when the key is not in the dictionary.
+	"get": {1, 2, func(_ *Interpreter, receiver *LoxDict, arguments []interface{}) (interface{}, error) {
+		value, ok, err := receiver.Get(arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			return arguments[1], nil
+		}
+		return value, nil
+	}},
+	// merge copies every entry of another dictionary into the receiver, replacing the values of keys it already has.
+	"merge": {1, 1, func(interpreter *Interpreter, receiver *LoxDict, arguments []interface{}) (interface{}, error) {
+		other, ok := arguments[0].(*LoxDict)
+		if !ok {
+			return nil, fmt.Errorf("Argument of merge must be a dictionary.")
+		}
+
+		keys, values := other.entries()
+		for i := range keys {
+			if _, ok, _ := receiver.Get(keys[i]); !ok {
+				if err := interpreter.allocate(Token{}, mapEntrySize); err != nil {
+					return nil, err
+				}
+			}
+
+			err := receiver.Set(keys[i], values[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}},
+}
+
+// getDictMethod returns the method called name, bound to receiver.
+func getDictMethod(receiver *LoxDict, name Token) (interface{}, error) {
+	method, ok := dictMethods[name.Lexeme]
+	if !ok {
+		return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+	}
+
+	return NewNativeFunctionWithOptional(name.Lexeme, method.minArity, method.arity, func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+		return method.fn(interpreter, receiver, arguments)
+	}), nil
+}
@@ -0,0 +1,25 @@
+package lox_interpreter_test
+
+import "testing"
+
+func TestPrintSelfContainingDict(t *testing.T) {
+	got, err := runScript(t, `
+var d = {a: 1};
+d.merge({self: d});
+print d;
+var e = {b: 2};
+var f = {e: e};
+e.merge({f: f});
+print e;
+var l = [d];
+print l;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "{a: 1, self: {...}}\n{b: 2, f: {e: {...}}}\n[{a: 1, self: {...}}]\n"
+	if got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+}
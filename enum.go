@@ -0,0 +1,93 @@
+package lox_interpreter
+
+import (
+	"fmt"
+)
+
+// LoxEnum is created by an `enum` declaration. Its variants are reached with `Color.Red`.
+type LoxEnum struct {
+	name     string
+	variants []*LoxEnumVariant
+}
+
+func NewLoxEnum(name string) *LoxEnum {
+	return &LoxEnum{
+		name: name,
+	}
+}
+
+func (e *LoxEnum) ToString() string {
+	return fmt.Sprintf("<enum %s>", e.name)
+}
+
+func (e *LoxEnum) addVariant(name string, fields []string) {
+	e.variants = append(e.variants, &LoxEnumVariant{e, name, fields})
+}
+
+func (e *LoxEnum) findVariant(name string) *LoxEnumVariant {
+	for _, variant := range e.variants {
+		if variant.name == name {
+			return variant
+		}
+	}
+
+	return nil
+}
+
+func (e *LoxEnum) Get(name Token) (interface{}, error) {
+	if variant := e.findVariant(name.Lexeme); variant != nil {
+		return variant, nil
+	}
+
+	return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined variant '%s' of enum %s.", name.Lexeme, e.name))
+}
+
+var _ Callable = (*LoxEnumVariant)(nil)
+
+// LoxEnumVariant is a variant of an enum. A variant without fields is a value by itself,
+// and a variant with fields is called like a function to create a LoxEnumValue.
+type LoxEnumVariant struct {
+	enum   *LoxEnum
+	name   string
+	fields []string
+}
+
+func (v *LoxEnumVariant) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(v.fields) == 0 {
+		return nil, fmt.Errorf("Variant %s has no fields.", v.ToString())
+	}
+
+	return &LoxEnumValue{v, arguments}, nil
+}
+
+func (v *LoxEnumVariant) Arity() int {
+	return len(v.fields)
+}
+
+func (v *LoxEnumVariant) ToString() string {
+	return v.enum.name + "." + v.name
+}
+
+func (v *LoxEnumVariant) Bind(instance *LoxInstance) Callable {
+	return v
+}
+
+// LoxEnumValue is a variant together with the values of its fields, such as `Result.Ok(1)`.
+type LoxEnumValue struct {
+	variant *LoxEnumVariant
+	values  []interface{}
+}
+
+func (v *LoxEnumValue) ToString() string {
+	return Stringify(v)
+}
+
+func (v *LoxEnumValue) Get(name Token) (interface{}, error) {
+	for i, field := range v.variant.fields {
+		if field == name.Lexeme {
+			return v.values[i], nil
+		}
+	}
+
+	return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
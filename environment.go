@@ -1,6 +1,9 @@
 package lox_interpreter
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 type EnvironmentError struct {
 	token   Token
@@ -15,15 +18,20 @@ func (e EnvironmentError) Error() string {
 	return fmt.Sprintf("%d at '%s' %s", e.token.LineNumber, e.token.Lexeme, e.message)
 }
 
+// Environment holds the bindings of one scope. It is safe for concurrent use,
+// because spawned functions share the globals and their closures with the spawning interpreter.
 type Environment struct {
 	Enclosing *Environment
 	Values    map[string]interface{}
+	constants map[string]bool
+	mu        sync.RWMutex
 }
 
 func NewEnvironment(enclosing *Environment) *Environment {
 	return &Environment{
 		Enclosing: enclosing,
 		Values:    make(map[string]interface{}),
+		constants: make(map[string]bool),
 	}
 }
 
@@ -46,14 +54,58 @@ func (e *Environment) depth() int {
 	return depth
 }
 
+func (e *Environment) lookup(name string) (interface{}, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	v, ok := e.Values[name]
+	return v, ok
+}
+
 func (e *Environment) Define(name string, value interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	e.Values[name] = value
+	delete(e.constants, name)
+}
+
+// DefineConstant defines a binding that can not be assigned again.
+func (e *Environment) DefineConstant(name string, value interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.Values[name] = value
+	e.constants[name] = true
+}
+
+func (e *Environment) IsConstant(name string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.constants[name]
+}
+
+// assign sets the value when the name is defined in this environment. ok is false when it is not.
+func (e *Environment) assign(name Token, value interface{}) (ok bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.Values[name.Lexeme]; !ok {
+		return false, nil
+	}
+
+	if e.constants[name.Lexeme] {
+		return true, NewEnvironmentError(name, fmt.Sprintf("Cannot assign to constant '%s'.", name.Lexeme))
+	}
+
+	e.Values[name.Lexeme] = value
+	return true, nil
 }
 
 func (e *Environment) Assign(name Token, value interface{}) error {
-	if _, ok := e.Values[name.Lexeme]; ok {
-		e.Values[name.Lexeme] = value
-		return nil
+	if ok, err := e.assign(name, value); ok {
+		return err
 	}
 
 	if e.Enclosing != nil {
@@ -64,16 +116,24 @@ func (e *Environment) Assign(name Token, value interface{}) error {
 }
 
 func (e *Environment) AssignAt(distance int, name Token, value interface{}) error {
-	if e.ancestor(distance) == nil {
+	env := e.ancestor(distance)
+	if env == nil {
 		return NewEnvironmentError(name, fmt.Sprintf("Invalid ancestor. current : %d, distance: %d", e.depth(), distance))
 	}
 
-	e.ancestor(distance).Values[name.Lexeme] = value
+	env.mu.Lock()
+	defer env.mu.Unlock()
+
+	if env.constants[name.Lexeme] {
+		return NewEnvironmentError(name, fmt.Sprintf("Cannot assign to constant '%s'.", name.Lexeme))
+	}
+
+	env.Values[name.Lexeme] = value
 	return nil
 }
 
 func (e *Environment) GetAt(distance int, name Token) (v interface{}, err error) {
-	v, ok := e.ancestor(distance).Values[name.Lexeme]
+	v, ok := e.ancestor(distance).lookup(name.Lexeme)
 	if !ok {
 		return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined variable '%s'", name.Lexeme))
 	}
@@ -82,7 +142,7 @@ func (e *Environment) GetAt(distance int, name Token) (v interface{}, err error)
 }
 
 func (e *Environment) GetAtWithString(distance int, name string) (v interface{}, err error) {
-	v, ok := e.ancestor(distance).Values[name]
+	v, ok := e.ancestor(distance).lookup(name)
 	if !ok {
 		return nil, fmt.Errorf("Undefined variable '%s'", name)
 	}
@@ -91,7 +151,7 @@ func (e *Environment) GetAtWithString(distance int, name string) (v interface{},
 }
 
 func (e *Environment) Get(name Token) (interface{}, error) {
-	if val, ok := e.Values[name.Lexeme]; ok {
+	if val, ok := e.lookup(name.Lexeme); ok {
 		return val, nil
 	}
 
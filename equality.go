@@ -0,0 +1,140 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// equalPair is a pair of values whose comparison is in progress.
+// Comparing a pair again while it is in progress means the values contain themselves, and the pair is taken as equal.
+type equalPair struct {
+	a, b interface{}
+}
+
+// isEqual compares lists, dictionaries, tuples and enum values by their elements, and instances by their `__eq__`
+// or `equals` method when their class defines one. Everything else is compared by identity.
+func (i *Interpreter) isEqual(a, b interface{}) (bool, error) {
+	return i.equal(a, b, make(map[equalPair]bool))
+}
+
+func (i *Interpreter) equal(a, b interface{}, inProgress map[equalPair]bool) (bool, error) {
+	switch a := a.(type) {
+	case *LoxList:
+		b, ok := b.(*LoxList)
+		if !ok {
+			return false, nil
+		}
+
+		return i.equalPointers(a, b, inProgress, func() (bool, error) {
+			return i.equalElements(a.snapshot(), b.snapshot(), inProgress)
+		})
+	case *LoxDict:
+		b, ok := b.(*LoxDict)
+		if !ok {
+			return false, nil
+		}
+
+		return i.equalPointers(a, b, inProgress, func() (bool, error) {
+			return i.equalDicts(a, b, inProgress)
+		})
+	case TupleType:
+		b, ok := b.(TupleType)
+		if !ok {
+			return false, nil
+		}
+
+		return i.equalElements(a, b, inProgress)
+	case *LoxEnumValue:
+		b, ok := b.(*LoxEnumValue)
+		if !ok || a.variant != b.variant {
+			return false, nil
+		}
+
+		return i.equalElements(a.values, b.values, inProgress)
+	case *LoxGoValue:
+		// a struct that is read twice is wrapped twice, so wrappers are equal when they point to the same struct.
+		b, ok := b.(*LoxGoValue)
+		return ok && a.value.Pointer() == b.value.Pointer() && a.value.Type() == b.value.Type(), nil
+	}
+
+	for _, name := range []string{"__eq__", "equals"} {
+		if equals := specialMethod(a, name); equals != nil {
+			return i.callEquals(name, equals, b)
+		}
+
+		if equals := specialMethod(b, name); equals != nil {
+			return i.callEquals(name, equals, a)
+		}
+	}
+
+	// values of an uncomparable Go type would make `==` panic.
+	if t := reflect.TypeOf(a); t != nil && !t.Comparable() {
+		return false, nil
+	}
+
+	return a == b, nil
+}
+
+// equalPointers compares two lists or two dictionaries with compare, unless they are the same value
+// or their comparison is already in progress.
+func (i *Interpreter) equalPointers(a, b interface{}, inProgress map[equalPair]bool, compare func() (bool, error)) (bool, error) {
+	pair := equalPair{a, b}
+	if a == b || inProgress[pair] {
+		return true, nil
+	}
+
+	inProgress[pair] = true
+	defer delete(inProgress, pair)
+
+	return compare()
+}
+
+func (i *Interpreter) equalElements(a, b []interface{}, inProgress map[equalPair]bool) (bool, error) {
+	if len(a) != len(b) {
+		return false, nil
+	}
+
+	for n := range a {
+		equal, err := i.equal(a[n], b[n], inProgress)
+		if err != nil || !equal {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// equalDicts reports whether a and b have the same keys with equal values, in any order.
+func (i *Interpreter) equalDicts(a, b *LoxDict, inProgress map[equalPair]bool) (bool, error) {
+	keys, values := a.entries()
+	if len(keys) != b.len() {
+		return false, nil
+	}
+
+	for n, key := range keys {
+		value, ok, err := b.Get(key)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		equal, err := i.equal(values[n], value, inProgress)
+		if err != nil || !equal {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (i *Interpreter) callEquals(name string, equals Callable, other interface{}) (bool, error) {
+	if equals.Arity() != 1 {
+		return false, fmt.Errorf("Method %s must take 1 argument.", name)
+	}
+
+	result, err := i.call(equals, []interface{}{other})
+	if err != nil {
+		return false, err
+	}
+
+	return i.condition(Token{}, result)
+}
@@ -0,0 +1,322 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type timer struct {
+	id       int
+	due      time.Time
+	interval time.Duration
+	repeat   bool
+	callback Callable
+}
+
+// EventLoop runs timer callbacks and resumes async functions. It is cooperative: everything it runs
+// happens on the goroutine that drives it, one task at a time.
+type EventLoop struct {
+	interpreter *Interpreter
+	mu          sync.Mutex
+	virtual     bool
+	current     time.Time
+	nextID      int
+	timers      map[int]*timer
+	queue       []func() error
+}
+
+func NewEventLoop(interpreter *Interpreter) *EventLoop {
+	return &EventLoop{
+		interpreter: interpreter,
+		timers:      make(map[int]*timer),
+	}
+}
+
+// useVirtualClock makes timers fire as soon as nothing else is left to run, moving the clock
+// forward to their due time instead of sleeping.
+func (l *EventLoop) useVirtualClock() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.virtual = true
+	l.current = time.Now()
+}
+
+func (l *EventLoop) now() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.virtual {
+		return l.current
+	}
+
+	return time.Now()
+}
+
+func (l *EventLoop) enqueue(task func() error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.queue = append(l.queue, task)
+}
+
+func (l *EventLoop) schedule(callback Callable, delay time.Duration, repeat bool) int {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	l.timers[l.nextID] = &timer{
+		id:       l.nextID,
+		due:      now.Add(delay),
+		interval: delay,
+		repeat:   repeat,
+		callback: callback,
+	}
+
+	return l.nextID
+}
+
+func (l *EventLoop) cancel(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.timers, id)
+}
+
+func (l *EventLoop) pop() func() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.queue) == 0 {
+		return nil
+	}
+
+	task := l.queue[0]
+	l.queue = l.queue[1:]
+	return task
+}
+
+func (l *EventLoop) nextTimer() *timer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var next *timer
+	for _, t := range l.timers {
+		if next == nil || t.due.Before(next.due) || (t.due.Equal(next.due) && t.id < next.id) {
+			next = t
+		}
+	}
+
+	return next
+}
+
+// fire waits until the timer is due, and queues its callback.
+// It fails when the context of the interpreter is done while waiting.
+func (l *EventLoop) fire(t *timer) error {
+	if wait := t.due.Sub(l.now()); wait > 0 {
+		l.mu.Lock()
+		virtual := l.virtual
+		if virtual {
+			l.current = t.due
+		}
+		l.mu.Unlock()
+
+		if !virtual {
+			wakeup := time.NewTimer(wait)
+			defer wakeup.Stop()
+
+			select {
+			case <-wakeup.C:
+			case <-l.interpreter.budget.ctx.Done():
+				return l.interpreter.interrupted(Token{})
+			}
+		}
+	}
+
+	l.mu.Lock()
+	if _, ok := l.timers[t.id]; !ok {
+		l.mu.Unlock()
+		return nil
+	}
+
+	if t.repeat {
+		t.due = t.due.Add(t.interval)
+	} else {
+		delete(l.timers, t.id)
+	}
+	l.mu.Unlock()
+
+	// callbacks run on a fork, so that they do not disturb the state of an interpreter that is awaiting.
+	l.enqueue(func() error {
+		_, err := l.interpreter.fork(nil).call(t.callback, nil)
+		return err
+	})
+	return nil
+}
+
+// runUntil runs queued tasks and fires timers until done returns true, or there is nothing left to run.
+// A nil done runs the loop until it is drained.
+func (l *EventLoop) runUntil(done func() bool) error {
+	for {
+		if done != nil && done() {
+			return nil
+		}
+
+		if task := l.pop(); task != nil {
+			if err := task(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		t := l.nextTimer()
+		if t == nil {
+			return nil
+		}
+
+		if err := l.fire(t); err != nil {
+			return err
+		}
+	}
+}
+
+// LoxPromise is returned by calling an async function. It settles when the function returns.
+type LoxPromise struct {
+	mu        sync.Mutex
+	settled   bool
+	value     interface{}
+	err       error
+	callbacks []func(value interface{}, err error)
+}
+
+func NewLoxPromise() *LoxPromise {
+	return &LoxPromise{}
+}
+
+func (p *LoxPromise) ToString() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case !p.settled:
+		return "<promise pending>"
+	case p.err != nil:
+		return "<promise rejected>"
+	default:
+		return "<promise fulfilled>"
+	}
+}
+
+func (p *LoxPromise) isSettled() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.settled
+}
+
+func (p *LoxPromise) result() (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.value, p.err
+}
+
+func (p *LoxPromise) settle(value interface{}, err error) {
+	p.mu.Lock()
+	if p.settled {
+		p.mu.Unlock()
+		return
+	}
+
+	p.settled = true
+	p.value = value
+	p.err = err
+	callbacks := p.callbacks
+	p.callbacks = nil
+	p.mu.Unlock()
+
+	for _, callback := range callbacks {
+		callback(value, err)
+	}
+}
+
+// then calls the callback once the promise has settled, right away if it already has.
+func (p *LoxPromise) then(callback func(value interface{}, err error)) {
+	p.mu.Lock()
+	if !p.settled {
+		p.callbacks = append(p.callbacks, callback)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+
+	callback(p.result())
+}
+
+var _ Callable = (*SetTimeout)(nil)
+
+// SetTimeout calls the function once after the given number of milliseconds. setInterval keeps calling it.
+type SetTimeout struct {
+	repeat bool
+}
+
+func (s SetTimeout) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	callback, ok := arguments[0].(Callable)
+	if !ok || callback.Arity() != 0 {
+		return nil, fmt.Errorf("First argument must be a function without parameters.")
+	}
+
+	ms, ok := arguments[1].(float64)
+	if !ok || ms < 0 {
+		return nil, fmt.Errorf("Second argument must be a non-negative number.")
+	}
+
+	return float64(interpreter.loop.schedule(callback, time.Duration(ms*float64(time.Millisecond)), s.repeat)), nil
+}
+
+func (s SetTimeout) Arity() int {
+	return 2
+}
+
+func (s SetTimeout) ToString() string {
+	if s.repeat {
+		return "<native fn setInterval>"
+	}
+	return "<native fn setTimeout>"
+}
+
+func (s SetTimeout) Bind(instance *LoxInstance) Callable {
+	return s
+}
+
+var _ Callable = (*ClearTimer)(nil)
+
+// ClearTimer cancels a timer created by setTimeout or setInterval.
+type ClearTimer struct {
+	name string
+}
+
+func (c ClearTimer) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	id, ok := arguments[0].(float64)
+	if !ok {
+		return nil, fmt.Errorf("Argument must be a timer id.")
+	}
+
+	interpreter.loop.cancel(int(id))
+	return nil, nil
+}
+
+func (c ClearTimer) Arity() int {
+	return 1
+}
+
+func (c ClearTimer) ToString() string {
+	return "<native fn " + c.name + ">"
+}
+
+func (c ClearTimer) Bind(instance *LoxInstance) Callable {
+	return c
+}
@@ -0,0 +1,94 @@
+package lox_interpreter_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+// runVirtual runs source on an interpreter with a virtual clock, and returns what it printed.
+func runVirtual(t *testing.T, source string) string {
+	t.Helper()
+
+	program, diagnostics := lox.Compile(source, lox.Config{})
+	if diagnostics != nil {
+		t.Fatal(diagnostics)
+	}
+
+	var out bytes.Buffer
+	interpreter := lox.NewInterpreter(nil, lox.WithStdout(&out))
+	interpreter.UseVirtualClock()
+	if _, err := interpreter.Run(program); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.String()
+}
+
+func TestTimersFireInOrderOfDueTime(t *testing.T) {
+	start := time.Now()
+	got := runVirtual(t, `
+var start = clock();
+fun at(name) {
+  fun callback() { print name + " " + inspect((clock() - start) / 1000000); }
+  return callback;
+}
+setTimeout(at("c"), 30000);
+setTimeout(at("a"), 10000);
+setTimeout(at("b"), 20000);
+setTimeout(at("a2"), 10000);
+print "sync";
+`)
+
+	want := "sync\na 10000\na2 10000\nb 20000\nc 30000\n"
+	if got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the virtual clock slept for %s", elapsed)
+	}
+}
+
+func TestIntervalsAndClearedTimers(t *testing.T) {
+	got := runVirtual(t, `
+var ticks = 0;
+var id;
+fun tick() {
+  ticks = ticks + 1;
+  print "tick " + inspect(ticks);
+  if (ticks == 3) clearInterval(id);
+}
+id = setInterval(tick, 1000);
+fun never() { print "never"; }
+clearTimeout(setTimeout(never, 500));
+fun between() { print "between"; }
+setTimeout(between, 1500);
+`)
+
+	want := "tick 1\nbetween\ntick 2\ntick 3\n"
+	if got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+}
+
+func TestAwaitResumesAfterSynchronousCode(t *testing.T) {
+	got := runVirtual(t, `
+async fun inner() { print "inner"; return 1; }
+async fun outer() {
+  print "outer before";
+  var value = await inner();
+  print "outer after " + inspect(value);
+}
+fun timer() { print "timer"; }
+setTimeout(timer, 0);
+outer();
+print "sync";
+`)
+
+	want := "outer before\ninner\nsync\nouter after 1\ntimer\n"
+	if got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+}
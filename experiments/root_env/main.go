@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	lox "github.com/ariyn/lox_interpreter"
 	"log"
 	"math/rand"
@@ -8,34 +9,19 @@ import (
 	"strings"
 )
 
-var _ (lox.Callable) = (*RandFunc)(nil)
-
-type RandFunc struct {
-}
-
-func (g RandFunc) Call(_ *lox.Interpreter, arguments []interface{}) (v interface{}, err error) {
+func randomWord(size, someArgs int) (string, error) {
 	x := strings.Split("abcdefghijklmnopqrstuvwxyz", "")
+	if size < 0 || size > len(x) {
+		return "", fmt.Errorf("size must be between 0 and %d", len(x))
+	}
+
 	rand.Shuffle(len(x), func(i, j int) {
 		x[i], x[j] = x[j], x[i]
 	})
-	log.Println(arguments)
-	size := int(arguments[0].(float64))
-	someArgs := int(arguments[1].(float64))
+	log.Println(size, someArgs)
 	return strings.Join(x, "")[:size] + strconv.Itoa(someArgs), nil
 }
 
-func (g RandFunc) Arity() int {
-	return 2
-}
-
-func (g RandFunc) ToString() string {
-	return "random word"
-}
-
-func (g RandFunc) Bind(instance *lox.LoxInstance) lox.Callable {
-	return g
-}
-
 func main() {
 	script := `print clock();
 var x = rand(3, 5);
@@ -44,12 +30,14 @@ print "RESULT " + x;
 	scanner := lox.NewScanner(script)
 	tokens, _ := scanner.ScanTokens()
 
-	parser := lox.NewParser(tokens)
+	parser := lox.NewParser(tokens, lox.Config{})
 	statements, _ := parser.Parse()
 
 	env := lox.NewEnvironment(nil)
-	env.Define("rand", &RandFunc{})
 	interpreter := lox.NewInterpreter(env)
+	if err := interpreter.RegisterFunc("rand", randomWord); err != nil {
+		panic(err)
+	}
 
 	resolver := lox.NewResolver(interpreter)
 	err := resolver.Resolve(statements...)
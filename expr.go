@@ -17,7 +17,16 @@ type ExprVisitor interface {
 	VisitDictionaryExpr(expr *DictionaryExpr) (interface{}, error)
 	VisitSelectExpr(expr *SelectExpr) (interface{}, error)
 	VisitListExpr(expr *ListExpr) (interface{}, error)
+	VisitOptionalExpr(expr *OptionalExpr) (interface{}, error)
+	VisitChainExpr(expr *ChainExpr) (interface{}, error)
+	VisitSpawnExpr(expr *SpawnExpr) (interface{}, error)
+	VisitAwaitExpr(expr *AwaitExpr) (interface{}, error)
+	VisitTupleExpr(expr *TupleExpr) (interface{}, error)
 }
+
+// Expr is a node of the syntax tree. Nodes are never changed after parsing: the resolver and the interpreter
+// keep their state in their own structures, so one tree can be run by interpreters on several goroutines at once.
+// TestConcurrentRuns checks this with the race detector.
 type Expr interface {
 	Accept(v ExprVisitor) (interface{}, error)
 }
@@ -265,12 +274,16 @@ func (e *SuperExpr) Accept(v ExprVisitor) (interface{}, error) {
 var _ Expr = (*DictionaryExpr)(nil)
 
 type DictionaryExpr struct {
-	mapExpr map[Token]Expr
+	brace  Token
+	keys   []Expr
+	values []Expr
 }
 
-func NewDictionaryExpr(mapExpr map[Token]Expr) *DictionaryExpr {
+func NewDictionaryExpr(brace Token, keys []Expr, values []Expr) *DictionaryExpr {
 	return &DictionaryExpr{
-		mapExpr,
+		brace,
+		keys,
+		values,
 	}
 }
 
@@ -311,3 +324,91 @@ func NewListExpr(values []Expr) *ListExpr {
 func (e *ListExpr) Accept(v ExprVisitor) (interface{}, error) {
 	return v.VisitListExpr(e)
 }
+
+var _ Expr = (*OptionalExpr)(nil)
+
+type OptionalExpr struct {
+	object   Expr
+	operator Token
+}
+
+func NewOptionalExpr(object Expr, operator Token) *OptionalExpr {
+	return &OptionalExpr{
+		object,
+		operator,
+	}
+}
+
+func (e *OptionalExpr) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitOptionalExpr(e)
+}
+
+var _ Expr = (*ChainExpr)(nil)
+
+type ChainExpr struct {
+	expression Expr
+}
+
+func NewChainExpr(expression Expr) *ChainExpr {
+	return &ChainExpr{
+		expression,
+	}
+}
+
+func (e *ChainExpr) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitChainExpr(e)
+}
+
+var _ Expr = (*SpawnExpr)(nil)
+
+type SpawnExpr struct {
+	keyword Token
+	call    *CallExpr
+}
+
+func NewSpawnExpr(keyword Token, call *CallExpr) *SpawnExpr {
+	return &SpawnExpr{
+		keyword,
+		call,
+	}
+}
+
+func (e *SpawnExpr) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitSpawnExpr(e)
+}
+
+var _ Expr = (*AwaitExpr)(nil)
+
+type AwaitExpr struct {
+	keyword Token
+	value   Expr
+}
+
+func NewAwaitExpr(keyword Token, value Expr) *AwaitExpr {
+	return &AwaitExpr{
+		keyword,
+		value,
+	}
+}
+
+func (e *AwaitExpr) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitAwaitExpr(e)
+}
+
+var _ Expr = (*TupleExpr)(nil)
+
+type TupleExpr struct {
+	paren  Token
+	values []Expr
+}
+
+func NewTupleExpr(paren Token, values []Expr) *TupleExpr {
+	return &TupleExpr{
+		paren,
+		values,
+	}
+}
+
+func (e *TupleExpr) Accept(v ExprVisitor) (interface{}, error) {
+	return v.VisitTupleExpr(e)
+}
@@ -0,0 +1,80 @@
+package lox_interpreter
+
+import "fmt"
+
+// LoxGenerator is returned by calling a function that contains `yield`.
+// A generator that is not run to the end keeps its goroutine parked until it is closed, which a for loop does
+// when it stops early and Interpret does when it returns, or until the context of the interpreter is done.
+type LoxGenerator struct {
+	function  *LoxFunction
+	coroutine *coroutine
+	peeked    *coroutineResult
+}
+
+func NewLoxGenerator(function *LoxFunction, interpreter *Interpreter, env *Environment) *LoxGenerator {
+	return &LoxGenerator{
+		function:  function,
+		coroutine: newCoroutine(interpreter, function.declaration.body, env, false),
+	}
+}
+
+func (g *LoxGenerator) ToString() string {
+	return "<generator " + g.function.declaration.name.Lexeme + ">"
+}
+
+// Next runs the generator until the next `yield`. ok is false once the body has finished.
+func (g *LoxGenerator) Next() (value interface{}, ok bool, err error) {
+	result := g.advance()
+	if result.done {
+		return nil, false, result.err
+	}
+
+	return result.value, true, nil
+}
+
+// Close stops a generator that has not run to the end, so that its goroutine exits. Later calls of Next report it as done.
+func (g *LoxGenerator) Close() {
+	g.peeked = nil
+	g.coroutine.close()
+}
+
+func (g *LoxGenerator) Get(name Token) (interface{}, error) {
+	switch name.Lexeme {
+	case "next":
+		return NewNativeFunction("next", 0, func(_ *Interpreter, _ []interface{}) (interface{}, error) {
+			value, _, err := g.Next()
+			return value, err
+		}), nil
+	case "done":
+		return NewNativeFunction("done", 0, func(_ *Interpreter, _ []interface{}) (interface{}, error) {
+			result := g.peek()
+			return result.done && result.err == nil, nil
+		}), nil
+	case "close":
+		return NewNativeFunction("close", 0, func(_ *Interpreter, _ []interface{}) (interface{}, error) {
+			g.Close()
+			return nil, nil
+		}), nil
+	}
+
+	return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
+
+func (g *LoxGenerator) advance() coroutineResult {
+	if g.peeked != nil {
+		result := *g.peeked
+		g.peeked = nil
+		return result
+	}
+
+	return g.coroutine.next(nil, nil)
+}
+
+func (g *LoxGenerator) peek() coroutineResult {
+	if g.peeked == nil {
+		result := g.advance()
+		g.peeked = &result
+	}
+
+	return *g.peeked
+}
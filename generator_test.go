@@ -0,0 +1,68 @@
+package lox_interpreter_test
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"time"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+// settledGoroutines waits for goroutines that are exiting, and returns how many are left.
+func settledGoroutines(limit int) int {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > limit && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return runtime.NumGoroutine()
+}
+
+func TestRunClosesSuspendedGenerators(t *testing.T) {
+	program, diagnostics := lox.Compile(`
+fun g() { var i = 0; while (true) { yield i; i = i + 1; } }
+for (var n = 0; n < 10; n = n + 1) {
+  var it = g();
+  it.next();
+}
+`, lox.Config{})
+	if diagnostics != nil {
+		t.Fatal(diagnostics)
+	}
+
+	before := runtime.NumGoroutine()
+	for run := 0; run < 20; run++ {
+		if _, err := lox.NewInterpreter(nil).Run(program); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if after := settledGoroutines(before); after > before {
+		t.Errorf("%d goroutines are left after the runs, %d were running before", after, before)
+	}
+}
+
+func TestGeneratorClosedByRunIsFinished(t *testing.T) {
+	var out bytes.Buffer
+	interpreter := lox.NewInterpreter(nil, lox.WithStdout(&out))
+
+	first, diagnostics := lox.Compile("fun g() { yield 1; yield 2; } var it = g(); print it.next();", lox.Config{})
+	if diagnostics != nil {
+		t.Fatal(diagnostics)
+	}
+	second, diagnostics := lox.Compile("print it.next();", lox.Config{}, "it")
+	if diagnostics != nil {
+		t.Fatal(diagnostics)
+	}
+
+	for _, program := range []*lox.Program{first, second} {
+		if _, err := interpreter.Run(program); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, want := out.String(), "1\nnil\n"; got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+}
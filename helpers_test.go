@@ -0,0 +1,23 @@
+package lox_interpreter_test
+
+import (
+	"bytes"
+	"testing"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+// runScript compiles source and runs it on a new interpreter, and returns what the script printed.
+func runScript(t *testing.T, source string, options ...lox.Option) (string, error) {
+	t.Helper()
+
+	program, diagnostics := lox.Compile(source, lox.Config{})
+	if diagnostics != nil {
+		t.Fatalf("compile: %v", diagnostics)
+	}
+
+	var out bytes.Buffer
+	options = append([]lox.Option{lox.WithStdout(&out)}, options...)
+	_, err := lox.NewInterpreter(nil, options...).Run(program)
+	return out.String(), err
+}
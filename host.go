@@ -0,0 +1,133 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Call calls the global function called name with arguments, and returns its result.
+// name may also be a path to a method such as "server.handle", which is looked up like a property.
+// Arguments and the result are converted like in CallValue.
+func (i *Interpreter) Call(name string, arguments ...interface{}) (interface{}, error) {
+	path := strings.Split(name, ".")
+
+	value, ok := i.Globals.lookup(path[0])
+	if !ok {
+		return nil, fmt.Errorf("undefined function '%s'", name)
+	}
+
+	for _, property := range path[1:] {
+		token := Token{Type: IDENTIFIER, Lexeme: property}
+
+		var err error
+		switch object := value.(type) {
+		case *LoxInstance:
+			value, err = object.Get(token)
+		case *LoxEnum:
+			value, err = object.Get(token)
+		case *LoxGoValue:
+			value, err = object.Get(token)
+		default:
+			return nil, fmt.Errorf("cannot call '%s': only instances have properties", name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return i.CallValue(value, arguments...)
+}
+
+// CallValue calls callable, which is a function, class or method that a script created, with arguments.
+// Arguments are converted from Go values like the results of RegisterFunc. The result is converted to a Go value:
+// lists and tuples become []interface{}, dictionaries become map[interface{}]interface{} and objects made by
+// BindValue become their struct pointer again. Async functions are awaited, and pending timers are run.
+//
+// Runtime errors carry the stack of calls that led to them. The interpreter is not safe for concurrent use,
+// so callers must not call it from several goroutines at once.
+func (i *Interpreter) CallValue(callable interface{}, arguments ...interface{}) (interface{}, error) {
+	if method := specialMethod(callable, "__call__"); method != nil {
+		callable = method
+	}
+
+	c, ok := callable.(Callable)
+	if !ok {
+		return nil, fmt.Errorf("cannot call %s: can only call functions and classes", Stringify(callable))
+	}
+
+	min, max := arityRange(c)
+	if len(arguments) < min || len(arguments) > max {
+		if min == max {
+			return nil, fmt.Errorf("cannot call %s: expected %d arguments but got %d", c.ToString(), max, len(arguments))
+		}
+		return nil, fmt.Errorf("cannot call %s: expected %d to %d arguments but got %d", c.ToString(), min, max, len(arguments))
+	}
+
+	values := make([]interface{}, len(arguments))
+	for a, argument := range arguments {
+		values[a] = fromGo(reflect.ValueOf(argument))
+	}
+
+	result, err := i.call(c, values)
+	if err != nil {
+		return nil, i.hostError(c, err)
+	}
+
+	if err := i.loop.runUntil(nil); err != nil {
+		return nil, i.hostError(c, err)
+	}
+
+	if promise, ok := result.(*LoxPromise); ok && promise.isSettled() {
+		result, err = promise.result()
+		if err != nil {
+			return nil, i.hostError(c, err)
+		}
+	}
+
+	return toHost(result), nil
+}
+
+// hostError makes err a RuntimeError, so that errors of natives also tell which function the host called.
+func (i *Interpreter) hostError(callable Callable, err error) error {
+	if _, ok := err.(*RuntimeError); ok {
+		return err
+	}
+
+	return &RuntimeError{Token{}, err.Error(), []Callable{callable}, err}
+}
+
+// toHost converts a Lox value to a Go value for the host. Values that have no Go counterpart are returned as they are.
+func toHost(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *LoxList:
+		return toHostElements(value.snapshot())
+	case TupleType:
+		return toHostElements(value)
+	case *LoxDict:
+		keys, values := value.entries()
+		converted := make(map[interface{}]interface{}, len(keys))
+		for k := range keys {
+			key := keys[k]
+			// tuples are slices in Go, which cannot be map keys.
+			if tuple, ok := key.(TupleType); ok {
+				key = tuple.ToString()
+			}
+			converted[key] = toHost(values[k])
+		}
+		return converted
+	case *LoxGoValue:
+		return value.value.Interface()
+	}
+
+	return value
+}
+
+func toHostElements(elements []interface{}) []interface{} {
+	converted := make([]interface{}, len(elements))
+	for e, element := range elements {
+		converted[e] = toHost(element)
+	}
+	return converted
+}
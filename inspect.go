@@ -0,0 +1,93 @@
+package lox_interpreter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var _ Callable = (*Inspect)(nil)
+
+// Inspect returns a debug representation of a value, which shows the class and the fields of instances
+// and quotes strings. It does not call toString methods.
+type Inspect struct{}
+
+func (n Inspect) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	return inspect(arguments[0], make(map[interface{}]bool)), nil
+}
+
+func (n Inspect) Arity() int {
+	return 1
+}
+
+func (n Inspect) ToString() string {
+	return "<native fn inspect>"
+}
+
+func (n Inspect) Bind(instance *LoxInstance) Callable {
+	return n
+}
+
+// inspect formats value. inProgress holds the instances, lists and dictionaries being formatted,
+// so a value that contains itself is shown as `...` instead of recursing forever.
+func inspect(value interface{}, inProgress map[interface{}]bool) string {
+	switch value := value.(type) {
+	case string:
+		return strconv.Quote(value)
+	case *LoxInstance:
+		if inProgress[value] {
+			return value.class.name + " {...}"
+		}
+		inProgress[value] = true
+		defer delete(inProgress, value)
+
+		fields := value.snapshot()
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		elements := make([]string, len(names))
+		for i, name := range names {
+			elements[i] = name + ": " + inspect(fields[name], inProgress)
+		}
+		return value.class.name + " {" + strings.Join(elements, ", ") + "}"
+	case *LoxList:
+		if inProgress[value] {
+			return "[...]"
+		}
+		inProgress[value] = true
+		defer delete(inProgress, value)
+
+		return "[" + inspectElements(value.snapshot(), inProgress) + "]"
+	case *LoxDict:
+		if inProgress[value] {
+			return "{...}"
+		}
+		inProgress[value] = true
+		defer delete(inProgress, value)
+
+		keys, values := value.entries()
+		elements := make([]string, len(keys))
+		for i := range keys {
+			elements[i] = inspect(keys[i], inProgress) + ": " + inspect(values[i], inProgress)
+		}
+		return "{" + strings.Join(elements, ", ") + "}"
+	case TupleType:
+		return "(" + inspectElements(value, inProgress) + ")"
+	case *LoxEnumValue:
+		return value.variant.ToString() + "(" + inspectElements(value.values, inProgress) + ")"
+	default:
+		return Stringify(value)
+	}
+}
+
+func inspectElements(values []interface{}, inProgress map[interface{}]bool) string {
+	elements := make([]string, len(values))
+	for i, value := range values {
+		elements[i] = inspect(value, inProgress)
+	}
+
+	return strings.Join(elements, ", ")
+}
@@ -1,44 +1,94 @@
 package lox_interpreter
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
 	"strings"
 )
 
-type ListType []interface{}
-type DictType map[string]interface{}
-
 type RuntimeError struct {
 	token     Token
 	message   string
 	callstack []Callable
+	cause     error
+}
+
+// Unwrap returns the cause of the error, such as ErrStackOverflow, or nil for errors of the script itself.
+func (r *RuntimeError) Unwrap() error {
+	return r.cause
 }
 
 func (r *RuntimeError) Error() string {
+	// errors that are not raised at a token, such as an exhausted budget, have no location.
+	if r.token.Lexeme == "" {
+		return fmt.Sprintf("%s\n%s", r.message, r.callstackToString())
+	}
 	return fmt.Sprintf("%d at '%s' %s\n%s", r.token.LineNumber, r.token.Lexeme, r.message, r.callstackToString())
 }
 
+// maxTraceFrames is how many of the innermost and of the outermost frames a stack trace shows.
+// A deep stack, such as the one of ErrStackOverflow, is shortened in between.
+const maxTraceFrames = 20
+
 func (r *RuntimeError) callstackToString() string {
-	var callstack string
+	var calls []string
 	for i := len(r.callstack) - 1; i >= 0; i-- {
-		c := r.callstack[i]
-
-		switch c.(type) {
+		switch c := r.callstack[i].(type) {
 		case *LoxFunction:
-			callstack += fmt.Sprintf("%s[line %d] in %s\n", strings.Repeat(" ", i), c.(*LoxFunction).declaration.name.LineNumber, c.ToString())
+			calls = append(calls, fmt.Sprintf("[line %d] in %s", c.declaration.name.LineNumber, c.ToString()))
 		case *LoxClass:
-			callstack += fmt.Sprintf("%s[line %d] in %s\n", strings.Repeat(" ", i), 0, c.ToString())
+			calls = append(calls, fmt.Sprintf("[line %d] in %s", 0, c.ToString()))
 		}
-		//callstack += fmt.Sprintf("%s[line %d] in %s\n", strings.Repeat(" ", i), .declaration.name.LineNumber, c.ToString())
 	}
 
-	return callstack
+	// a frame that repeats, like the ones of a recursive function, is shown once with the number of repeats.
+	var frames []string
+	for c := 0; c < len(calls); {
+		next := c + 1
+		for next < len(calls) && calls[next] == calls[c] {
+			next++
+		}
+
+		if repeats := next - c - 1; repeats > 0 {
+			frames = append(frames, fmt.Sprintf("%s (repeated %d more times)\n", calls[c], repeats))
+		} else {
+			frames = append(frames, calls[c]+"\n")
+		}
+		c = next
+	}
+
+	var callstack strings.Builder
+	if len(frames) <= 2*maxTraceFrames {
+		for _, frame := range frames {
+			callstack.WriteString(frame)
+		}
+		return callstack.String()
+	}
+
+	for _, frame := range frames[:maxTraceFrames] {
+		callstack.WriteString(frame)
+	}
+	fmt.Fprintf(&callstack, "... %d more\n", len(frames)-2*maxTraceFrames)
+	for _, frame := range frames[len(frames)-maxTraceFrames:] {
+		callstack.WriteString(frame)
+	}
+	return callstack.String()
 }
 
 func NewRuntimeError(token Token, message string, callstack []Callable) error {
-	return &RuntimeError{token, message, callstack}
+	// the call stack keeps changing after the error is returned, so the error keeps a copy.
+	return &RuntimeError{token, message, append([]Callable(nil), callstack...), nil}
 }
 
+// errShortCircuit is returned by an optional link (`?.`, `?.(`, `?[`) whose object is nil.
+// It unwinds to the enclosing ChainExpr, which turns it into nil.
+var errShortCircuit = errors.New("optional chain short-circuited")
+
 var _ StmtVisitor = (*Interpreter)(nil)
 var _ ExprVisitor = (*Interpreter)(nil)
 
@@ -49,25 +99,102 @@ type Interpreter struct {
 	breakCurrentLoop bool
 	isReturningValue bool
 	localsTable      map[Expr]int
-	callStack        []Callable
+	// programs holds the resolution of each Program that was run. The programs may be shared, so it is only read.
+	programs     []map[Expr]int
+	callStack    []Callable
+	coroutine    *coroutine
+	loop         *EventLoop
+	stdout       io.Writer
+	stderr       io.Writer
+	stdin        *syncReader
+	budget       *budget
+	tasks        *tasks
+	coroutines   *coroutines
+	capabilities Capabilities
+	config       Config
 }
 
-func NewInterpreter(env *Environment) *Interpreter {
+// NewInterpreter creates an interpreter whose globals live in env, which is created when it is nil.
+// Without options it reads from os.Stdin, writes to os.Stdout and os.Stderr, and has the DefaultCapabilities.
+func NewInterpreter(env *Environment, options ...Option) *Interpreter {
 	if env == nil {
 		env = NewEnvironment(nil)
 	}
 
+	defineNatives(env)
+
+	interpreter := &Interpreter{
+		Env:          env,
+		Globals:      env,
+		localsTable:  make(map[Expr]int),
+		stdout:       &syncWriter{w: os.Stdout},
+		stderr:       &syncWriter{w: os.Stderr},
+		stdin:        &syncReader{r: bufio.NewReader(os.Stdin)},
+		budget:       newBudget(),
+		tasks:        newTasks(),
+		coroutines:   newCoroutines(),
+		capabilities: DefaultCapabilities(),
+	}
+	for _, option := range options {
+		option(interpreter)
+	}
+	interpreter.loop = NewEventLoop(interpreter)
+
+	return interpreter
+}
+
+// defineNatives defines the native functions that every script can call.
+func defineNatives(env *Environment) {
 	env.Define("clock", &Clock{})
 	env.Define("len", &Len{})
+	env.Define("freeze", &Freeze{})
+	env.Define("inspect", &Inspect{})
+	env.Define("input", &Input{})
+	env.Define("readFile", &ReadFile{})
+	env.Define("writeFile", &WriteFile{})
+	env.Define("exec", &Exec{})
+	env.Define("getenv", &Getenv{})
+	env.Define("random", &Random{})
+	env.Define("channel", &Channel{})
+	env.Define("setTimeout", &SetTimeout{})
+	env.Define("setInterval", &SetTimeout{repeat: true})
+	env.Define("clearTimeout", &ClearTimer{"clearTimeout"})
+	env.Define("clearInterval", &ClearTimer{"clearInterval"})
+}
+
+// UseVirtualClock makes timers fire without sleeping. `clock()` reports the virtual time.
+func (i *Interpreter) UseVirtualClock() {
+	i.loop.useVirtualClock()
+}
 
+// fork creates an interpreter that shares the globals and the resolution of this one,
+// but keeps its own environment, call stack and control flow state.
+func (i *Interpreter) fork(c *coroutine) *Interpreter {
 	return &Interpreter{
-		Env:         env,
-		Globals:     env,
-		localsTable: make(map[Expr]int),
+		Env:          i.Env,
+		Globals:      i.Globals,
+		localsTable:  i.localsTable,
+		programs:     i.programs,
+		callStack:    append([]Callable(nil), i.callStack...),
+		coroutine:    c,
+		loop:         i.loop,
+		stdout:       i.stdout,
+		stderr:       i.stderr,
+		stdin:        i.stdin,
+		budget:       i.budget,
+		tasks:        i.tasks,
+		coroutines:   i.coroutines,
+		capabilities: i.capabilities,
+		config:       i.config,
 	}
 }
 
+// Interpret executes the statements, and then runs the event loop until no timers or async functions are left.
+// Generators and async functions that are still suspended when it returns are closed, so their goroutines exit;
+// resuming such a generator later finds it finished.
 func (i *Interpreter) Interpret(expr []Stmt) (value interface{}, err error) {
+	defer i.coroutines.closeAll()
+
 	for _, stmt := range expr {
 		var _err error
 		value, _err = i.execute(stmt)
@@ -78,6 +205,11 @@ func (i *Interpreter) Interpret(expr []Stmt) (value interface{}, err error) {
 		}
 	}
 
+	err = i.loop.runUntil(nil)
+	if err != nil {
+		return nil, err
+	}
+
 	return value, err
 }
 
@@ -86,6 +218,10 @@ func (i *Interpreter) execute(stmt Stmt) (interface{}, error) {
 		return nil, nil
 	}
 
+	if err := i.step(); err != nil {
+		return nil, err
+	}
+
 	value, err := stmt.Accept(i)
 	if err != nil {
 		return nil, err
@@ -132,13 +268,52 @@ func (i *Interpreter) VisitVarStmt(expr *VarStmt) (v interface{}, err error) {
 	return nil, nil // TODO: Find out why not returning the value.
 }
 
+// VisitUnpackStmt is function for unpacking a tuple into variables. such as `var q, r = divmod(7, 2);`
+func (i *Interpreter) VisitUnpackStmt(stmt *UnpackStmt) (interface{}, error) {
+	value, err := i.Evaluate(stmt.initializer)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+	switch value := value.(type) {
+	case TupleType:
+		values = value
+	case *LoxList:
+		values = value.snapshot()
+	default:
+		return nil, NewRuntimeError(stmt.equals, "Can only unpack tuples and lists.", i.callStack)
+	}
+
+	if len(values) != len(stmt.names) {
+		return nil, NewRuntimeError(stmt.equals, fmt.Sprintf("Expected %d values to unpack but got %d.", len(stmt.names), len(values)), i.callStack)
+	}
+
+	for n, name := range stmt.names {
+		i.Env.Define(name.Lexeme, values[n])
+	}
+
+	return nil, nil
+}
+
+// VisitConstStmt is function for constant statement. such as `const A = 1;`
+func (i *Interpreter) VisitConstStmt(stmt *ConstStmt) (interface{}, error) {
+	value, err := i.Evaluate(stmt.initializer)
+	if err != nil {
+		return nil, err
+	}
+
+	i.Env.DefineConstant(stmt.name.Lexeme, value)
+	return nil, nil
+}
+
 func (i *Interpreter) VisitFunStmt(expr *FunStmt) (interface{}, error) {
 	function := NewFunction(expr, i.Env, false)
 	i.Env.Define(expr.name.Lexeme, function)
 	return nil, nil
 }
 
-func (i *Interpreter) VisitClassStmt(stmt *ClassStmt) (_ interface{}, err error) {
+func (i *Interpreter) VisitClassStmt(stmt *ClassStmt) (interface{}, error) {
 	i.Env.Define(stmt.name.Lexeme, nil)
 
 	var superclass *LoxClass = nil
@@ -155,34 +330,86 @@ func (i *Interpreter) VisitClassStmt(stmt *ClassStmt) (_ interface{}, err error)
 		superclass = spc.(*LoxClass)
 	}
 
+	var traits []*LoxTrait
+	for _, t := range stmt.traits {
+		value, err := i.Evaluate(t)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := value.(*LoxTrait); !ok {
+			return nil, NewRuntimeError(t.name, "Can only mix in traits.", i.callStack)
+		}
+
+		traits = append(traits, value.(*LoxTrait))
+	}
+
 	i.Env.Define(stmt.name.Lexeme, nil)
 
+	// methods close over an environment holding `super`, which i.Env itself never becomes.
+	closure := i.Env
 	if stmt.superClass != nil {
-		i.Env = NewEnvironment(i.Env)
-		i.Env.Define("super", superclass)
+		closure = NewEnvironment(i.Env)
+		closure.Define("super", superclass)
 	}
 
 	methods := make(map[string]Callable)
 	for _, method := range stmt.methods {
-		function := NewFunction(method, i.Env, method.name.Lexeme == "init")
-		methods[method.name.Lexeme] = function
+		methods[method.name.Lexeme] = NewFunction(method, closure, method.name.Lexeme == "init")
 	}
-	class := NewLoxClass(stmt.name.Lexeme, superclass, methods)
 
-	if superclass != nil {
-		i.Env = i.Env.Enclosing
+	// methods defined in the class itself override the ones from traits.
+	traitOf := make(map[string]*LoxTrait)
+	for _, trait := range traits {
+		for name, method := range trait.bindTo(superclass) {
+			if _, ok := methods[name]; ok && traitOf[name] == nil {
+				continue
+			}
+
+			if other, ok := traitOf[name]; ok {
+				return nil, NewRuntimeError(stmt.name, fmt.Sprintf("Method '%s' is defined in both trait '%s' and trait '%s'.", name, other.name, trait.name), i.callStack)
+			}
+
+			traitOf[name] = trait
+			methods[name] = method
+		}
+	}
+
+	class := NewLoxClass(stmt.name.Lexeme, superclass, methods)
+	if err := i.Env.Assign(stmt.name, class); err != nil {
+		return nil, err
 	}
-	err = i.Env.Assign(stmt.name, class)
 
 	return class, nil
 }
 
+func (i *Interpreter) VisitTraitStmt(stmt *TraitStmt) (interface{}, error) {
+	trait := NewLoxTrait(stmt.name.Lexeme, stmt.methods, i.Env)
+	i.Env.Define(stmt.name.Lexeme, trait)
+	return nil, nil
+}
+
+func (i *Interpreter) VisitEnumStmt(stmt *EnumStmt) (interface{}, error) {
+	enum := NewLoxEnum(stmt.name.Lexeme)
+	for n, variant := range stmt.variants {
+		fields := make([]string, len(stmt.fields[n]))
+		for f, field := range stmt.fields[n] {
+			fields[f] = field.Lexeme
+		}
+
+		enum.addVariant(variant.Lexeme, fields)
+	}
+
+	i.Env.Define(stmt.name.Lexeme, enum)
+	return nil, nil
+}
+
 func (i *Interpreter) VisitThisExpr(expr *ThisExpr) (interface{}, error) {
 	return i.lookupTable(expr.keyword, expr)
 }
 
 func (i *Interpreter) VisitSuperExpr(expr *SuperExpr) (interface{}, error) {
-	distance := i.localsTable[expr]
+	distance, _ := i.local(expr)
 	spc, err := i.Env.GetAtWithString(distance, "super")
 	if err != nil {
 		return nil, err
@@ -192,33 +419,52 @@ func (i *Interpreter) VisitSuperExpr(expr *SuperExpr) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	if object == nil {
+	superclass, _ := spc.(*LoxClass)
+	if object == nil || superclass == nil {
 		return nil, NewRuntimeError(expr.keyword, "Cannot use 'super' in a class with no superclass.", i.callStack)
 	}
 	if _, ok := object.(*LoxInstance); !ok {
 		return nil, NewRuntimeError(expr.keyword, "Cannot use 'super' in a class with no superclass.", i.callStack)
 	}
 
-	method := spc.(*LoxClass).findMethod(expr.method.Lexeme)
+	method := superclass.findMethod(expr.method.Lexeme)
 	if method == nil {
 		return nil, NewRuntimeError(expr.method, fmt.Sprintf("Undefined property '%s'.", expr.method.Lexeme), i.callStack)
 	}
 
-	return method.(*LoxFunction).Bind(object.(*LoxInstance)), nil
+	return method.Bind(object.(*LoxInstance)), nil
 }
 
 func (i *Interpreter) VisitDictionaryExpr(expr *DictionaryExpr) (interface{}, error) {
-	dict := make(DictType)
-	for k, v := range expr.mapExpr {
-		value, err := i.Evaluate(v)
+	dict := NewLoxDict()
+	for n := range expr.keys {
+		key, err := i.Evaluate(expr.keys[n])
 		if err != nil {
 			return nil, err
 		}
 
-		if _, ok := dict[k.Lexeme]; ok {
-			return nil, NewRuntimeError(k, "Duplicate key in dictionary.", i.callStack)
+		value, err := i.Evaluate(expr.values[n])
+		if err != nil {
+			return nil, err
+		}
+
+		_, ok, err := dict.Get(key)
+		if err != nil {
+			return nil, NewRuntimeError(expr.brace, err.Error(), i.callStack)
 		}
-		dict[k.Lexeme] = value
+
+		if ok {
+			return nil, NewRuntimeError(expr.brace, "Duplicate key in dictionary.", i.callStack)
+		}
+
+		err = dict.Set(key, value)
+		if err != nil {
+			return nil, NewRuntimeError(expr.brace, err.Error(), i.callStack)
+		}
+	}
+
+	if err := i.allocate(expr.brace, sizeOf(dict)); err != nil {
+		return nil, err
 	}
 
 	return dict, nil
@@ -230,22 +476,32 @@ func (i *Interpreter) VisitSelectExpr(expr *SelectExpr) (interface{}, error) {
 		return nil, err
 	}
 
-	if dict, ok := object.(DictType); ok {
-		name, err := i.Evaluate(expr.name)
+	if method := specialMethod(object, "__index__"); method != nil {
+		index, err := i.Evaluate(expr.name)
+		if err != nil {
+			return nil, err
+		}
+
+		return i.callSpecialMethod(Token{}, "__index__", method, index)
+	}
+
+	if dict, ok := object.(*LoxDict); ok {
+		key, err := i.Evaluate(expr.name)
 		if err != nil {
 			return nil, err
 		}
 
-		if _, ok := name.(string); !ok {
-			return nil, NewRuntimeError(Token{}, "Property name must be a string.", i.callStack)
+		value, ok, err := dict.Get(key)
+		if err != nil {
+			return nil, NewRuntimeError(Token{}, err.Error(), i.callStack)
 		}
 
-		if value, ok := dict[name.(string)]; ok {
+		if ok {
 			return value, nil
 		}
 
-		return nil, NewRuntimeError(Token{}, fmt.Sprintf("Undefined property '%s'.", name.(string)), i.callStack)
-	} else if list, ok := object.(ListType); ok {
+		return nil, NewRuntimeError(Token{}, fmt.Sprintf("Undefined property '%s'.", Stringify(key)), i.callStack)
+	} else if list, ok := object.(*LoxList); ok {
 		index, err := i.Evaluate(expr.name)
 		if err != nil {
 			return nil, err
@@ -256,18 +512,35 @@ func (i *Interpreter) VisitSelectExpr(expr *SelectExpr) (interface{}, error) {
 			return nil, NewRuntimeError(Token{}, "Index must be a number.", i.callStack)
 		}
 
-		if int(v) < 0 || int(v) >= len(list) {
+		element, ok := list.get(int(v))
+		if !ok {
 			return nil, NewRuntimeError(Token{}, fmt.Sprintf("Index out of range: %d", int(v)), i.callStack)
 		}
 
-		return list[int(v)], nil
+		return element, nil
+	} else if tuple, ok := object.(TupleType); ok {
+		index, err := i.Evaluate(expr.name)
+		if err != nil {
+			return nil, err
+		}
+
+		v, ok := index.(float64)
+		if !ok {
+			return nil, NewRuntimeError(Token{}, "Index must be a number.", i.callStack)
+		}
+
+		if int(v) < 0 || int(v) >= len(tuple) {
+			return nil, NewRuntimeError(Token{}, fmt.Sprintf("Index out of range: %d", int(v)), i.callStack)
+		}
+
+		return tuple[int(v)], nil
 	}
 
-	return nil, NewRuntimeError(Token{}, "Only dictionaries or list can have properties.", i.callStack)
+	return nil, NewRuntimeError(Token{}, "Only dictionaries, lists or tuples can have properties.", i.callStack)
 }
 
 func (i *Interpreter) VisitListExpr(expr *ListExpr) (interface{}, error) {
-	var values ListType
+	var values []interface{}
 	for _, v := range expr.values {
 		value, err := i.Evaluate(v)
 		if err != nil {
@@ -277,9 +550,54 @@ func (i *Interpreter) VisitListExpr(expr *ListExpr) (interface{}, error) {
 		values = append(values, value)
 	}
 
+	list := NewLoxList(values)
+	if err := i.allocate(Token{}, sizeOf(list)); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (i *Interpreter) VisitTupleExpr(expr *TupleExpr) (interface{}, error) {
+	values := make(TupleType, len(expr.values))
+	for n, v := range expr.values {
+		value, err := i.Evaluate(v)
+		if err != nil {
+			return nil, err
+		}
+
+		values[n] = value
+	}
+
+	if err := i.allocate(expr.paren, sizeOf(values)); err != nil {
+		return nil, err
+	}
+
 	return values, nil
 }
 
+func (i *Interpreter) VisitOptionalExpr(expr *OptionalExpr) (interface{}, error) {
+	object, err := i.Evaluate(expr.object)
+	if err != nil {
+		return nil, err
+	}
+
+	if object == nil {
+		return nil, errShortCircuit
+	}
+
+	return object, nil
+}
+
+func (i *Interpreter) VisitChainExpr(expr *ChainExpr) (interface{}, error) {
+	value, err := i.Evaluate(expr.expression)
+	if errors.Is(err, errShortCircuit) {
+		return nil, nil
+	}
+
+	return value, err
+}
+
 func (i *Interpreter) VisitExpressionStmt(expr *ExpressionStmt) (interface{}, error) {
 	_, err := i.Evaluate(expr.expression)
 	return nil, err
@@ -291,8 +609,13 @@ func (i *Interpreter) VisitPrintStmt(expr *PrintStmt) (interface{}, error) {
 		return nil, err
 	}
 
-	fmt.Println(Stringify(value))
-	return nil, nil
+	s, err := i.stringify(expr.keyword, value)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintln(i.stdout, s)
+	return nil, err
 }
 
 func (i *Interpreter) VisitIfStmt(expr *IfStmt) (interface{}, error) {
@@ -301,7 +624,12 @@ func (i *Interpreter) VisitIfStmt(expr *IfStmt) (interface{}, error) {
 		return nil, err
 	}
 
-	if i.isTruthy(condition) {
+	truthy, err := i.condition(Token{}, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if truthy {
 		return i.execute(expr.thenBranch)
 	} else if expr.elseBranch != nil {
 		return i.execute(expr.elseBranch)
@@ -322,12 +650,25 @@ func (i *Interpreter) VisitWhileStmt(expr *WhileStmt) (interface{}, error) {
 		return nil, err
 	}
 
-	for i.isTruthy(condition) {
-		_, err = i.execute(expr.body)
+	for {
+		truthy, err := i.condition(Token{}, condition)
+		if err != nil {
+			return nil, err
+		}
+
+		if !truthy {
+			break
+		}
+
+		value, err := i.execute(expr.body)
 		if err != nil {
 			return nil, err
 		}
 
+		if i.isReturningValue {
+			return value, nil
+		}
+
 		condition, err = i.Evaluate(expr.condition)
 		if err != nil {
 			return nil, err
@@ -337,6 +678,106 @@ func (i *Interpreter) VisitWhileStmt(expr *WhileStmt) (interface{}, error) {
 	return nil, nil
 }
 
+func (i *Interpreter) VisitForInStmt(stmt *ForInStmt) (value interface{}, err error) {
+	i.currentLoop = stmt
+	defer func() {
+		i.currentLoop = nil
+		i.breakCurrentLoop = false
+	}()
+
+	iterable, err := i.Evaluate(stmt.iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.iterate(stmt.name, iterable, func(element interface{}) (bool, error) {
+		env := NewEnvironment(i.Env)
+		env.Define(stmt.name.Lexeme, element)
+
+		value, err = i.executeBlock([]Stmt{stmt.body}, env)
+		if err != nil {
+			return false, err
+		}
+
+		return !i.breakCurrentLoop && !i.isReturningValue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if i.isReturningValue {
+		return value, nil
+	}
+
+	return nil, nil
+}
+
+// iterate calls fn with every element of a list or a tuple, every key of a dictionary, every character of a string,
+// every value of a generator or every variant of an enum, until fn returns false.
+func (i *Interpreter) iterate(token Token, iterable interface{}, fn func(element interface{}) (bool, error)) error {
+	switch iterable := iterable.(type) {
+	case *LoxList:
+		for _, element := range iterable.snapshot() {
+			if ok, err := fn(element); !ok || err != nil {
+				return err
+			}
+		}
+	case TupleType:
+		for _, element := range iterable {
+			if ok, err := fn(element); !ok || err != nil {
+				return err
+			}
+		}
+	case *LoxDict:
+		keys, _ := iterable.entries()
+		for _, key := range keys {
+			if ok, err := fn(key); !ok || err != nil {
+				return err
+			}
+		}
+	case string:
+		for _, c := range iterable {
+			if ok, err := fn(string(c)); !ok || err != nil {
+				return err
+			}
+		}
+	case *LoxChannel:
+		for {
+			element, ok, err := iterable.Receive(i, token)
+			if err != nil || !ok {
+				return err
+			}
+
+			if ok, err := fn(element); !ok || err != nil {
+				return err
+			}
+		}
+	case *LoxGenerator:
+		// a loop that stops early, by break, return or an error, abandons the generator.
+		defer iterable.Close()
+		for {
+			element, ok, err := iterable.Next()
+			if err != nil || !ok {
+				return err
+			}
+
+			if ok, err := fn(element); !ok || err != nil {
+				return err
+			}
+		}
+	case *LoxEnum:
+		for _, variant := range iterable.variants {
+			if ok, err := fn(variant); !ok || err != nil {
+				return err
+			}
+		}
+	default:
+		return NewRuntimeError(token, "Can only iterate over lists, tuples, dictionaries, strings, channels, generators and enums.", i.callStack)
+	}
+
+	return nil
+}
+
 func (i *Interpreter) VisitBreakStmt(expr *BreakStmt) (interface{}, error) {
 	i.breakCurrentLoop = true
 	return nil, nil
@@ -356,6 +797,23 @@ func (i *Interpreter) VisitReturnStmt(expr *ReturnStmt) (v interface{}, err erro
 	return value, nil
 }
 
+func (i *Interpreter) VisitYieldStmt(stmt *YieldStmt) (v interface{}, err error) {
+	if i.coroutine == nil || i.coroutine.isAsync {
+		return nil, NewRuntimeError(stmt.keyword, "Cannot yield outside of a generator.", i.callStack)
+	}
+
+	var value interface{} = nil
+	if stmt.value != nil {
+		value, err = i.Evaluate(stmt.value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = i.coroutine.suspend(value)
+	return nil, err
+}
+
 func (i *Interpreter) VisitBlockStmt(expr *BlockStmt) (interface{}, error) {
 	return i.executeBlock(expr.statements, NewEnvironment(i.Env))
 }
@@ -365,6 +823,10 @@ func (i *Interpreter) Evaluate(expr Expr) (interface{}, error) {
 		return nil, nil
 	}
 
+	if err := i.step(); err != nil {
+		return nil, err
+	}
+
 	return expr.Accept(i)
 }
 
@@ -374,7 +836,7 @@ func (i *Interpreter) VisitAssignExpr(expr *AssignExpr) (interface{}, error) {
 		return nil, err
 	}
 
-	if distance, ok := i.localsTable[expr]; ok {
+	if distance, ok := i.local(expr); ok {
 		err = i.Env.AssignAt(distance, expr.name, value)
 	} else {
 		err = i.Globals.Assign(expr.name, value)
@@ -394,12 +856,17 @@ func (i *Interpreter) VisitLogicalExpr(expr *LogicalExpr) (interface{}, error) {
 	}
 
 	switch expr.operator.Type {
-	case OR:
-		if i.isTruthy(left) {
+	case OR, AND:
+		truthy, err := i.condition(expr.operator, left)
+		if err != nil {
+			return nil, err
+		}
+
+		if truthy == (expr.operator.Type == OR) {
 			return left, nil
 		}
-	case AND:
-		if !i.isTruthy(left) {
+	case QUESTION_QUESTION:
+		if left != nil {
 			return left, nil
 		}
 	}
@@ -413,7 +880,12 @@ func (i *Interpreter) VisitTernaryExpr(expr *TernaryExpr) (interface{}, error) {
 		return nil, err
 	}
 
-	if i.isTruthy(condition) {
+	truthy, err := i.condition(expr.question, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if truthy {
 		return i.Evaluate(expr.left)
 	}
 
@@ -428,6 +900,10 @@ func (i *Interpreter) VisitUnaryExpr(expr *UnaryExpr) (interface{}, error) {
 
 	switch expr.operator.Type {
 	case MINUS:
+		if method := specialMethod(right, "__neg__"); method != nil {
+			return i.callSpecialMethod(expr.operator, "__neg__", method)
+		}
+
 		isNumber := i.isAllNumber(right)
 		if !isNumber {
 			return nil, NewRuntimeError(expr.operator, "Operand must be a number.", i.callStack) // TODO: return error
@@ -435,46 +911,331 @@ func (i *Interpreter) VisitUnaryExpr(expr *UnaryExpr) (interface{}, error) {
 
 		return -right.(float64), nil
 	case BANG:
-		return !i.isTruthy(right), nil
+		truthy, err := i.condition(expr.operator, right)
+		if err != nil {
+			return nil, err
+		}
+
+		return !truthy, nil
 	}
 
 	return nil, nil // TODO: return error
 }
 
 func (i *Interpreter) VisitCallExpr(expr *CallExpr) (interface{}, error) {
-	defer func() {
-		i.isReturningValue = false
-	}()
+	callable, arguments, err := i.evaluateCall(expr)
+	if err != nil {
+		return nil, err
+	}
 
+	value, err := i.call(callable, arguments)
+	if r, ok := err.(*RuntimeError); ok && r.token.Lexeme == "" && !isBudgetError(r) {
+		// errors of native functions have no location of their own, so they are reported at the call.
+		r.token = expr.paren
+	}
+	return value, err
+}
+
+// evaluateCall evaluates the callee and the arguments of a call, and checks that they can be called.
+func (i *Interpreter) evaluateCall(expr *CallExpr) (Callable, []interface{}, error) {
 	callee, err := i.Evaluate(expr.callee)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var arguments []interface{}
 	for _, argument := range expr.arguments {
 		value, err := i.Evaluate(argument)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		arguments = append(arguments, value)
 	}
 
+	if method := specialMethod(callee, "__call__"); method != nil {
+		callee = method
+	}
+
 	callable, isCallable := callee.(Callable)
 	if !isCallable {
-		return nil, NewRuntimeError(expr.paren, "Can only call functions and classes.", i.callStack)
+		return nil, nil, NewRuntimeError(expr.paren, "Can only call functions and classes.", i.callStack)
+	}
+
+	min, max := arityRange(callable)
+	if len(arguments) < min || len(arguments) > max {
+		if min == max {
+			return nil, nil, NewRuntimeError(expr.paren, fmt.Sprintf("Expected %d arguments but got %d.", max, len(arguments)), i.callStack)
+		}
+		return nil, nil, NewRuntimeError(expr.paren, fmt.Sprintf("Expected %d to %d arguments but got %d.", min, max, len(arguments)), i.callStack)
 	}
 
-	if len(arguments) != callable.Arity() {
-		return nil, NewRuntimeError(expr.paren, fmt.Sprintf("Expected %d arguments but got %d.", callable.Arity(), len(arguments)), i.callStack)
+	return callable, arguments, nil
+}
+
+func (i *Interpreter) call(callable Callable, arguments []interface{}) (interface{}, error) {
+	defer func() {
+		i.isReturningValue = false
+	}()
+
+	if max := i.budget.maxCallDepth; max > 0 && len(i.callStack) >= max {
+		var token Token
+		if function, ok := callable.(*LoxFunction); ok {
+			token = function.declaration.name
+		}
+		return nil, i.budgetError(token, ErrStackOverflow)
 	}
 
 	i.callStack = append(i.callStack, callable)
 	defer func() {
 		i.callStack = i.callStack[:len(i.callStack)-1]
 	}()
-	return callable.Call(i, arguments)
+
+	value, err := callable.Call(i, arguments)
+	if err != nil {
+		return nil, i.asRuntimeError(err)
+	}
+	return value, nil
+}
+
+// asRuntimeError makes err, such as an error of a native function, a RuntimeError that carries the current call stack.
+// It is called in the innermost frame, so the stack is the one where the error happened.
+func (i *Interpreter) asRuntimeError(err error) error {
+	callstack := append([]Callable(nil), i.callStack...)
+
+	switch e := err.(type) {
+	case *RuntimeError:
+		return err
+	case EnvironmentError:
+		return &RuntimeError{e.token, e.message, callstack, err}
+	}
+
+	return &RuntimeError{Token{}, err.Error(), callstack, err}
+}
+
+// equalityError reports errors of `__eq__` and `equals` methods that have no location of their own at operator.
+func (i *Interpreter) equalityError(operator Token, err error) error {
+	r := i.asRuntimeError(err).(*RuntimeError)
+	if r.token.Lexeme == "" {
+		r.token = operator
+	}
+	return r
+}
+
+// VisitSpawnExpr runs the call on its own goroutine with a forked interpreter, and returns a task for it.
+func (i *Interpreter) VisitSpawnExpr(expr *SpawnExpr) (interface{}, error) {
+	callable, arguments, err := i.evaluateCall(expr.call)
+	if err != nil {
+		return nil, err
+	}
+
+	task := NewLoxTask(callable.ToString())
+	forked := i.fork(nil)
+	i.tasks.update(1, 0)
+	go func() {
+		defer i.tasks.update(-1, 0)
+		task.complete(forked.call(callable, arguments))
+	}()
+
+	return task, nil
+}
+
+func (i *Interpreter) VisitAwaitExpr(expr *AwaitExpr) (interface{}, error) {
+	value, err := i.Evaluate(expr.value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := value.(type) {
+	case *LoxTask:
+		if _, _, _, err := i.wait(expr.keyword, []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(value.done)}}); err != nil {
+			return nil, err
+		}
+		return value.Wait()
+	case *LoxPromise:
+		// inside an async function, suspend and let the event loop resume us once the promise settles.
+		if i.coroutine != nil && i.coroutine.isAsync {
+			return i.coroutine.suspend(value)
+		}
+
+		err = i.loop.runUntil(value.isSettled)
+		if err != nil {
+			return nil, err
+		}
+
+		if !value.isSettled() {
+			return nil, NewRuntimeError(expr.keyword, "Awaited promise can never settle.", i.callStack)
+		}
+
+		return value.result()
+	}
+
+	return nil, NewRuntimeError(expr.keyword, "Can only await tasks and promises.", i.callStack)
+}
+
+// startAsync runs the body of an async function until its first `await`, and returns a promise for its result.
+func (i *Interpreter) startAsync(env *Environment, body []Stmt) *LoxPromise {
+	promise := NewLoxPromise()
+	c := newCoroutine(i, body, env, true)
+
+	var step func(value interface{}, err error) error
+	step = func(value interface{}, err error) error {
+		result := c.next(value, err)
+		if result.done {
+			promise.settle(result.value, result.err)
+			return nil
+		}
+
+		result.value.(*LoxPromise).then(func(value interface{}, err error) {
+			i.loop.enqueue(func() error {
+				return step(value, err)
+			})
+		})
+		return nil
+	}
+
+	_ = step(nil, nil)
+	return promise
+}
+
+func (i *Interpreter) VisitSelectStmt(stmt *SelectStmt) (_ interface{}, err error) {
+	cases := make([]reflect.SelectCase, 0, len(stmt.cases)+1)
+	for _, c := range stmt.cases {
+		object, err := i.Evaluate(c.channel)
+		if err != nil {
+			return nil, err
+		}
+
+		channel, ok := object.(*LoxChannel)
+		if !ok {
+			return nil, NewRuntimeError(c.operation, "Can only select on channels.", i.callStack)
+		}
+
+		if c.value == nil {
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(channel.ch)})
+			continue
+		}
+
+		value, err := i.Evaluate(c.value)
+		if err != nil {
+			return nil, err
+		}
+
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: reflect.ValueOf(channel.ch), Send: reflect.ValueOf(&value).Elem()})
+	}
+
+	if stmt.defaultBranch != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	}
+
+	chosen, received, err := i.selectCase(stmt.keyword, cases, stmt.defaultBranch == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if chosen == len(stmt.cases) {
+		return i.execute(stmt.defaultBranch)
+	}
+
+	c := stmt.cases[chosen]
+	env := NewEnvironment(i.Env)
+	if c.name.Lexeme != "" {
+		env.Define(c.name.Lexeme, received)
+	}
+
+	return i.executeBlock([]Stmt{c.body}, env)
+}
+
+// selectCase selects one of cases. Without a default case it waits like channel operations do.
+func (i *Interpreter) selectCase(keyword Token, cases []reflect.SelectCase, wait bool) (chosen int, received interface{}, err error) {
+	defer func() {
+		if recover() != nil {
+			err = NewRuntimeError(keyword, "Cannot send on a closed channel.", i.callStack)
+		}
+	}()
+
+	var value reflect.Value
+	var ok bool
+	if wait {
+		chosen, value, ok, err = i.wait(keyword, cases)
+	} else {
+		chosen, value, ok = reflect.Select(cases)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if ok {
+		received = value.Interface()
+	}
+
+	return chosen, received, nil
+}
+
+func (i *Interpreter) VisitMatchStmt(stmt *MatchStmt) (interface{}, error) {
+	value, err := i.Evaluate(stmt.value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arm := range stmt.arms {
+		env, ok, err := i.matchArm(arm, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			return i.executeBlock([]Stmt{arm.body}, env)
+		}
+	}
+
+	if stmt.defaultBranch != nil {
+		return i.execute(stmt.defaultBranch)
+	}
+
+	return nil, nil
+}
+
+// matchArm reports whether value matches the pattern of arm, and returns the environment
+// holding the fields the arm destructures.
+func (i *Interpreter) matchArm(arm *MatchArmStmt, value interface{}) (*Environment, bool, error) {
+	pattern, err := i.Evaluate(arm.pattern)
+	if err != nil {
+		return nil, false, err
+	}
+
+	env := NewEnvironment(i.Env)
+	if arm.variant.Lexeme == "" {
+		ok, err := i.isEqual(value, pattern)
+		return env, ok, err
+	}
+
+	variant, ok := pattern.(*LoxEnumVariant)
+	if !ok {
+		return nil, false, NewRuntimeError(arm.variant, "Can only destructure enum variants.", i.callStack)
+	}
+
+	if len(arm.bindings) != len(variant.fields) {
+		return nil, false, NewRuntimeError(arm.variant, fmt.Sprintf("Expected %d fields but got %d.", len(variant.fields), len(arm.bindings)), i.callStack)
+	}
+
+	enumValue, ok := value.(*LoxEnumValue)
+	if !ok || enumValue.variant != variant {
+		return nil, false, nil
+	}
+
+	for n, binding := range arm.bindings {
+		env.Define(binding.Lexeme, enumValue.values[n])
+	}
+
+	return env, true, nil
+}
+
+func (i *Interpreter) VisitMatchArmStmt(stmt *MatchArmStmt) (interface{}, error) {
+	return nil, NewRuntimeError(stmt.keyword, "Cannot use 'case' outside of a match.", i.callStack)
+}
+
+func (i *Interpreter) VisitCaseStmt(stmt *CaseStmt) (interface{}, error) {
+	return nil, NewRuntimeError(stmt.keyword, "Cannot use 'case' outside of a select.", i.callStack)
 }
 
 func (i *Interpreter) VisitGetExpr(expr *GetExpr) (v interface{}, err error) {
@@ -483,8 +1244,29 @@ func (i *Interpreter) VisitGetExpr(expr *GetExpr) (v interface{}, err error) {
 		return
 	}
 
-	if instance, ok := object.(*LoxInstance); ok {
-		return instance.Get(expr.name)
+	switch object := object.(type) {
+	case *LoxInstance:
+		return object.Get(expr.name)
+	case *LoxGenerator:
+		return object.Get(expr.name)
+	case *LoxChannel:
+		return object.Get(expr.name)
+	case *LoxEnum:
+		return object.Get(expr.name)
+	case *LoxEnumValue:
+		return object.Get(expr.name)
+	case string:
+		return getStringMethod(object, expr.name)
+	case *LoxList:
+		return getListMethod(object, expr.name)
+	case *LoxDict:
+		return getDictMethod(object, expr.name)
+	case *LoxGoValue:
+		value, err := object.Get(expr.name)
+		if err != nil {
+			return nil, err
+		}
+		return i.account(expr.name, value)
 	}
 
 	return nil, NewRuntimeError(expr.name, "Only instances have properties.", i.callStack)
@@ -496,6 +1278,18 @@ func (i *Interpreter) VisitSetExpr(expr *SetExpr) (v interface{}, err error) {
 		return
 	}
 
+	if g, ok := object.(*LoxGoValue); ok {
+		value, err := i.Evaluate(expr.value)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := g.Set(expr.name, value); err != nil {
+			return nil, NewRuntimeError(expr.name, err.Error(), i.callStack)
+		}
+		return nil, nil
+	}
+
 	instance, ok := object.(*LoxInstance)
 	if !ok {
 		return nil, NewRuntimeError(expr.name, "Only instances have fields.", i.callStack)
@@ -506,7 +1300,31 @@ func (i *Interpreter) VisitSetExpr(expr *SetExpr) (v interface{}, err error) {
 		return nil, err
 	}
 
-	return nil, instance.Set(expr.name, value)
+	if !instance.has(expr.name.Lexeme) {
+		if err := i.allocate(expr.name, mapEntrySize+int64(len(expr.name.Lexeme))); err != nil {
+			return nil, err
+		}
+	}
+
+	err = instance.Set(expr.name, value)
+	if err != nil {
+		return nil, NewRuntimeError(expr.name, err.Error(), i.callStack)
+	}
+
+	return nil, nil
+}
+
+// condition reports whether value, which decides a branch, is truthy. In strict mode it must be a boolean.
+func (i *Interpreter) condition(token Token, value interface{}) (bool, error) {
+	if b, ok := value.(bool); ok {
+		return b, nil
+	}
+
+	if i.config.Strict {
+		return false, NewRuntimeError(token, "Condition must be a boolean.", i.callStack)
+	}
+
+	return i.isTruthy(value), nil
 }
 
 func (i *Interpreter) isTruthy(value interface{}) bool {
@@ -532,6 +1350,10 @@ func (i *Interpreter) VisitBinaryExpr(expr *BinaryExpr) (interface{}, error) {
 		return nil, err
 	}
 
+	if value, ok, err := i.overloadBinary(expr.operator, left, right); ok || err != nil {
+		return value, err
+	}
+
 	switch expr.operator.Type {
 	case MINUS:
 		if !i.isAllNumber(left, right) {
@@ -548,6 +1370,10 @@ func (i *Interpreter) VisitBinaryExpr(expr *BinaryExpr) (interface{}, error) {
 			return nil, NewRuntimeError(expr.operator, "Division by zero.", i.callStack)
 		}
 
+		if i.config.IntegerDivision && isInteger(left.(float64)) && isInteger(right.(float64)) {
+			return math.Trunc(left.(float64) / right.(float64)), nil
+		}
+
 		return left.(float64) / right.(float64), nil
 	case STAR:
 		if !i.isAllNumber(left, right) {
@@ -560,11 +1386,25 @@ func (i *Interpreter) VisitBinaryExpr(expr *BinaryExpr) (interface{}, error) {
 			return left.(float64) + right.(float64), nil
 		}
 		if i.isAllString(left, right) {
-			return left.(string) + right.(string), nil
+			return i.concatenate(expr.operator, left.(string), right.(string))
 		}
 
-		if useCrossAddition && i.isAllStringOrNumber(left, right) {
-			return Stringify(left) + Stringify(right), nil
+		if i.config.CrossAddition && i.isAllStringOrNumber(left, right) {
+			return i.concatenate(expr.operator, Stringify(left), Stringify(right))
+		}
+
+		if i.isConcatenation(left, right) || i.isConcatenation(right, left) {
+			l, err := i.stringify(expr.operator, left)
+			if err != nil {
+				return nil, err
+			}
+
+			r, err := i.stringify(expr.operator, right)
+			if err != nil {
+				return nil, err
+			}
+
+			return i.concatenate(expr.operator, l, r)
 		}
 
 		return nil, NewRuntimeError(expr.operator, "Operands must be two numbers or two strings.", i.callStack)
@@ -599,9 +1439,17 @@ func (i *Interpreter) VisitBinaryExpr(expr *BinaryExpr) (interface{}, error) {
 		}
 		return nil, NewRuntimeError(expr.operator, "Operands must be two numbers or two strings.", i.callStack)
 	case EQUAL_EQUAL:
-		return left == right, nil
+		equal, err := i.isEqual(left, right)
+		if err != nil {
+			return nil, i.equalityError(expr.operator, err)
+		}
+		return equal, nil
 	case BANG_EQUAL:
-		return left != right, nil
+		equal, err := i.isEqual(left, right)
+		if err != nil {
+			return nil, i.equalityError(expr.operator, err)
+		}
+		return !equal, nil
 	}
 
 	return nil, nil // TODO: return error
@@ -635,8 +1483,23 @@ func (i *Interpreter) ResolveExpression(expr Expr, depth int) (_ interface{}, er
 	return
 }
 
-func (i *Interpreter) lookupTable(name Token, expr Expr) (v interface{}, err error) {
+// local returns the depth of the local variable that expr refers to, as the resolver or a Program recorded it.
+func (i *Interpreter) local(expr Expr) (int, bool) {
 	if depth, ok := i.localsTable[expr]; ok {
+		return depth, true
+	}
+
+	for _, locals := range i.programs {
+		if depth, ok := locals[expr]; ok {
+			return depth, true
+		}
+	}
+
+	return 0, false
+}
+
+func (i *Interpreter) lookupTable(name Token, expr Expr) (v interface{}, err error) {
+	if depth, ok := i.local(expr); ok {
 		v, err := i.Env.GetAt(depth, name)
 		if err != nil {
 			return nil, NewRuntimeError(name, err.Error(), i.callStack)
@@ -686,6 +1549,49 @@ func (i *Interpreter) isAllStringOrNumber(possibles ...interface{}) bool {
 	return true
 }
 
+// stringify is Stringify that calls the toString method of instances, also of the ones inside lists, dictionaries,
+// tuples and enum values, and reports their errors at token.
+func (i *Interpreter) stringify(token Token, value interface{}) (string, error) {
+	return formatValue(value, make(map[interface{}]bool), func(instance *LoxInstance) (string, error) {
+		method := specialMethod(instance, "toString")
+		if method == nil {
+			return instance.ToString(), nil
+		}
+
+		result, err := i.callSpecialMethod(token, "toString", method)
+		if err != nil {
+			return "", err
+		}
+
+		s, ok := result.(string)
+		if !ok {
+			return "", NewRuntimeError(token, "Method toString must return a string.", i.callStack)
+		}
+
+		return s, nil
+	})
+}
+
+// concatenate accounts for the memory of the result before creating it, so a string that doubles in a loop
+// runs out of quota instead of memory.
+func (i *Interpreter) concatenate(operator Token, left, right string) (interface{}, error) {
+	if len(left)+len(right) > maxStringSize {
+		return nil, NewRuntimeError(operator, "String is too large.", i.callStack)
+	}
+
+	if err := i.allocate(operator, stringHeaderSize+int64(len(left))+int64(len(right))); err != nil {
+		return nil, err
+	}
+
+	return left + right, nil
+}
+
+// isConcatenation reports whether `+` of a string and an instance with a toString method concatenates them.
+func (i *Interpreter) isConcatenation(s, instance interface{}) bool {
+	_, ok := s.(string)
+	return ok && specialMethod(instance, "toString") != nil
+}
+
 func Stringify(d interface{}) string {
 	switch d.(type) {
 	case *LiteralExpr:
@@ -699,7 +1605,89 @@ func Stringify(d interface{}) string {
 		return d.(Callable).ToString()
 	case *LoxInstance:
 		return d.(*LoxInstance).ToString()
+	case *LoxTrait:
+		return d.(*LoxTrait).ToString()
+	case *LoxGenerator:
+		return d.(*LoxGenerator).ToString()
+	case *LoxTask:
+		return d.(*LoxTask).ToString()
+	case *LoxChannel:
+		return d.(*LoxChannel).ToString()
+	case *LoxPromise:
+		return d.(*LoxPromise).ToString()
+	case *LoxEnum:
+		return d.(*LoxEnum).ToString()
+	case *LoxEnumValue, TupleType, *LoxList, *LoxDict:
+		s, _ := formatValue(d, make(map[interface{}]bool), nil)
+		return s
+	case *LoxGoValue:
+		return d.(*LoxGoValue).ToString()
 	default:
 		return toString(d)
 	}
 }
+
+// formatValue formats value like Stringify, together with the values that lists, dictionaries, tuples and enum values
+// hold. instance formats the instances among them, or they are shown as `<inst Name>` when it is nil.
+// inProgress holds the lists and dictionaries being formatted, so one that contains itself is shown as `[...]`
+// or `{...}` instead of recursing forever.
+func formatValue(value interface{}, inProgress map[interface{}]bool, instance func(*LoxInstance) (string, error)) (string, error) {
+	switch value := value.(type) {
+	case *LoxList:
+		if inProgress[value] {
+			return "[...]", nil
+		}
+		inProgress[value] = true
+		defer delete(inProgress, value)
+
+		elements, err := formatElements(value.snapshot(), inProgress, instance)
+		return "[" + elements + "]", err
+	case *LoxDict:
+		if inProgress[value] {
+			return "{...}", nil
+		}
+		inProgress[value] = true
+		defer delete(inProgress, value)
+
+		keys, values := value.entries()
+		elements := make([]string, len(keys))
+		for i := range keys {
+			key, err := formatValue(keys[i], inProgress, instance)
+			if err != nil {
+				return "", err
+			}
+
+			value, err := formatValue(values[i], inProgress, instance)
+			if err != nil {
+				return "", err
+			}
+			elements[i] = key + ": " + value
+		}
+		return "{" + strings.Join(elements, ", ") + "}", nil
+	case TupleType:
+		elements, err := formatElements(value, inProgress, instance)
+		return "(" + elements + ")", err
+	case *LoxEnumValue:
+		elements, err := formatElements(value.values, inProgress, instance)
+		return value.variant.ToString() + "(" + elements + ")", err
+	case *LoxInstance:
+		if instance != nil {
+			return instance(value)
+		}
+	}
+
+	return Stringify(value), nil
+}
+
+func formatElements(values []interface{}, inProgress map[interface{}]bool, instance func(*LoxInstance) (string, error)) (string, error) {
+	elements := make([]string, len(values))
+	for i, value := range values {
+		element, err := formatValue(value, inProgress, instance)
+		if err != nil {
+			return "", err
+		}
+		elements[i] = element
+	}
+
+	return strings.Join(elements, ", "), nil
+}
@@ -0,0 +1,110 @@
+package lox_interpreter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Option configures an Interpreter created by NewInterpreter.
+type Option func(*Interpreter)
+
+// WithStdout makes `print` write to w instead of os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stdout = &syncWriter{w: w}
+	}
+}
+
+// WithStderr makes the interpreter write diagnostics to w instead of os.Stderr.
+func WithStderr(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stderr = &syncWriter{w: w}
+	}
+}
+
+// WithStdin makes `input` read from r instead of os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(i *Interpreter) {
+		i.stdin = &syncReader{r: bufio.NewReader(r)}
+	}
+}
+
+// syncWriter serializes writes, because spawned functions and timers of one interpreter share its output.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.w.Write(p)
+}
+
+type syncReader struct {
+	mu sync.Mutex
+	r  *bufio.Reader
+}
+
+// readLine returns the next line without its line ending. ok is false at the end of the input.
+func (s *syncReader) readLine() (line string, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err = s.r.ReadString('\n')
+	if err == io.EOF {
+		return line, line != "", nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	return strings.TrimRight(line, "\r\n"), true, nil
+}
+
+var _ Callable = (*Input)(nil)
+var _ optionalArity = (*Input)(nil)
+
+// Input writes the optional prompt and reads a line of input. It returns nil at the end of the input.
+type Input struct{}
+
+func (n Input) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) == 1 {
+		prompt, err := interpreter.stringify(Token{}, arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = fmt.Fprint(interpreter.stdout, prompt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	line, ok, err := interpreter.stdin.readLine()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return line, interpreter.allocate(Token{}, sizeOf(line))
+}
+
+func (n Input) Arity() int {
+	return 1
+}
+
+func (n Input) MinArity() int {
+	return 0
+}
+
+func (n Input) ToString() string {
+	return "<native fn input>"
+}
+
+func (n Input) Bind(instance *LoxInstance) Callable {
+	return n
+}
@@ -0,0 +1,341 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LoxList is the value of a list literal such as `[1, 2, 3]`.
+// It is a reference, so methods like push change the list that every variable holding it sees.
+type LoxList struct {
+	values []interface{}
+	frozen bool
+	mu     sync.RWMutex
+}
+
+func NewLoxList(values []interface{}) *LoxList {
+	return &LoxList{
+		values: values,
+	}
+}
+
+func (l *LoxList) ToString() string {
+	return Stringify(l)
+}
+
+// snapshot returns a copy of the elements, which callers can use without holding the lock.
+func (l *LoxList) snapshot() []interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	values := make([]interface{}, len(l.values))
+	copy(values, l.values)
+	return values
+}
+
+func (l *LoxList) len() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.values)
+}
+
+func (l *LoxList) get(index int) (interface{}, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if index < 0 || index >= len(l.values) {
+		return nil, false
+	}
+	return l.values[index], true
+}
+
+func (l *LoxList) freeze() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.frozen = true
+}
+
+// modify calls fn with the elements while holding the lock, unless the list is frozen.
+// fn returns the new elements.
+func (l *LoxList) modify(fn func(values []interface{}) ([]interface{}, error)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.frozen {
+		return fmt.Errorf("Cannot modify a frozen list.")
+	}
+
+	values, err := fn(l.values)
+	if err != nil {
+		return err
+	}
+
+	l.values = values
+	return nil
+}
+
+// listMethod is a native method of lists. Arguments after the first minArity may be left out, and are nil then.
+// Methods that create values or grow the receiver account for the memory they allocate.
+type listMethod struct {
+	minArity int
+	arity    int
+	fn       func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error)
+}
+
+var listMethods = map[string]listMethod{
+	"push": {1, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		if err := interpreter.allocate(Token{}, valueSize); err != nil {
+			return nil, err
+		}
+
+		return nil, receiver.modify(func(values []interface{}) ([]interface{}, error) {
+			return append(values, arguments[0]), nil
+		})
+	}},
+	"pop": {0, 0, func(_ *Interpreter, receiver *LoxList, _ []interface{}) (interface{}, error) {
+		var last interface{}
+		err := receiver.modify(func(values []interface{}) ([]interface{}, error) {
+			if len(values) == 0 {
+				return nil, fmt.Errorf("Cannot pop from an empty list.")
+			}
+
+			last = values[len(values)-1]
+			return values[:len(values)-1], nil
+		})
+		return last, err
+	}},
+	"insert": {2, 2, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		if err := interpreter.allocate(Token{}, valueSize); err != nil {
+			return nil, err
+		}
+
+		return nil, receiver.modify(func(values []interface{}) ([]interface{}, error) {
+			index, err := indexArgument("insert", arguments[0], len(values))
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, nil)
+			copy(values[index+1:], values[index:])
+			values[index] = arguments[1]
+			return values, nil
+		})
+	}},
+	// remove removes the first element equal to the argument, and reports whether there was one.
+	"remove": {1, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		// the elements are compared without holding the lock, because an `equals` method may use the list.
+		for i, value := range receiver.snapshot() {
+			equal, err := interpreter.isEqual(value, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			if equal {
+				return true, receiver.modify(func(values []interface{}) ([]interface{}, error) {
+					if i >= len(values) {
+						return values, nil
+					}
+					return append(values[:i], values[i+1:]...), nil
+				})
+			}
+		}
+		return false, nil
+	}},
+	"indexOf": {1, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		for i, value := range receiver.snapshot() {
+			equal, err := interpreter.isEqual(value, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			if equal {
+				return float64(i), nil
+			}
+		}
+		return float64(-1), nil
+	}},
+	"contains": {1, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		for _, value := range receiver.snapshot() {
+			equal, err := interpreter.isEqual(value, arguments[0])
+			if err != nil {
+				return nil, err
+			}
+
+			if equal {
+				return true, nil
+			}
+		}
+		return false, nil
+	}},
+	"map": {1, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		values := receiver.snapshot()
+		for i, value := range values {
+			mapped, err := callFunction(interpreter, "map", arguments[0], value)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = mapped
+		}
+		return interpreter.account(Token{}, NewLoxList(values))
+	}},
+	"filter": {1, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		var values []interface{}
+		for _, value := range receiver.snapshot() {
+			keep, err := callFunction(interpreter, "filter", arguments[0], value)
+			if err != nil {
+				return nil, err
+			}
+
+			ok, err := interpreter.condition(Token{}, keep)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				values = append(values, value)
+			}
+		}
+		return interpreter.account(Token{}, NewLoxList(values))
+	}},
+	"reduce": {2, 2, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		accumulator := arguments[1]
+		for _, value := range receiver.snapshot() {
+			var err error
+			accumulator, err = callFunction(interpreter, "reduce", arguments[0], accumulator, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return accumulator, nil
+	}},
+	// sort sorts the list in place. Without a comparator the elements must be all numbers or all strings.
+	// A comparator is called with two elements and returns a negative number when the first one comes first.
+	"sort": {0, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		values := receiver.snapshot()
+
+		var err error
+		less := func(a, b interface{}) bool {
+			if err != nil {
+				return false
+			}
+
+			var result interface{}
+			result, err = callFunction(interpreter, "sort", arguments[0], a, b)
+			n, ok := result.(float64)
+			if err == nil && !ok {
+				err = fmt.Errorf("Comparator of sort must return a number.")
+			}
+			return n < 0
+		}
+
+		if arguments[0] == nil {
+			switch {
+			case interpreter.isAllNumber(values...):
+				less = func(a, b interface{}) bool { return a.(float64) < b.(float64) }
+			case interpreter.isAllString(values...):
+				less = func(a, b interface{}) bool { return a.(string) < b.(string) }
+			default:
+				return nil, fmt.Errorf("Can only sort numbers or strings without a comparator.")
+			}
+		}
+
+		sort.SliceStable(values, func(i, j int) bool {
+			return less(values[i], values[j])
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, receiver.modify(func(_ []interface{}) ([]interface{}, error) {
+			return values, nil
+		})
+	}},
+	"reverse": {0, 0, func(_ *Interpreter, receiver *LoxList, _ []interface{}) (interface{}, error) {
+		return nil, receiver.modify(func(values []interface{}) ([]interface{}, error) {
+			for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+				values[i], values[j] = values[j], values[i]
+			}
+			return values, nil
+		})
+	}},
+	"join": {1, 1, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		sep, err := stringArgument("join", arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		values := receiver.snapshot()
+		elements := make([]string, len(values))
+		for i, value := range values {
+			elements[i], err = interpreter.stringify(Token{}, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return interpreter.account(Token{}, strings.Join(elements, sep))
+	}},
+	// slice returns a new list with the elements from start up to, but not including, end.
+	"slice": {1, 2, func(interpreter *Interpreter, receiver *LoxList, arguments []interface{}) (interface{}, error) {
+		values := receiver.snapshot()
+		start, err := indexArgument("slice", arguments[0], len(values))
+		if err != nil {
+			return nil, err
+		}
+
+		end := len(values)
+		if arguments[1] != nil {
+			end, err = indexArgument("slice", arguments[1], len(values))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if start > end {
+			return nil, fmt.Errorf("Start of slice must not be after its end.")
+		}
+		return interpreter.account(Token{}, NewLoxList(values[start:end]))
+	}},
+}
+
+// getListMethod returns the method called name, bound to receiver.
+func getListMethod(receiver *LoxList, name Token) (interface{}, error) {
+	method, ok := listMethods[name.Lexeme]
+	if !ok {
+		return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+	}
+
+	return NewNativeFunctionWithOptional(name.Lexeme, method.minArity, method.arity, func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+		return method.fn(interpreter, receiver, arguments)
+	}), nil
+}
+
+// indexArgument checks that argument is an integer between 0 and length.
+func indexArgument(method string, argument interface{}, length int) (int, error) {
+	index, ok := argument.(float64)
+	if !ok || index != float64(int(index)) {
+		return 0, fmt.Errorf("Index of %s must be an integer.", method)
+	}
+
+	if index < 0 || int(index) > length {
+		return 0, fmt.Errorf("Index out of range: %d", int(index))
+	}
+
+	return int(index), nil
+}
+
+// callFunction calls fn, which is an argument of a native method, with the given arguments.
+func callFunction(interpreter *Interpreter, method string, fn interface{}, arguments ...interface{}) (interface{}, error) {
+	callable, ok := fn.(Callable)
+	if !ok {
+		return nil, fmt.Errorf("Argument of %s must be a function.", method)
+	}
+
+	if min, max := arityRange(callable); len(arguments) < min || len(arguments) > max {
+		return nil, fmt.Errorf("Function passed to %s must take %d arguments.", method, len(arguments))
+	}
+
+	return interpreter.call(callable, arguments)
+}
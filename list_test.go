@@ -0,0 +1,22 @@
+package lox_interpreter_test
+
+import "testing"
+
+func TestPrintSelfContainingList(t *testing.T) {
+	got, err := runScript(t, `
+var s = [1];
+s.push(s);
+print s;
+var t = [2];
+t.push((t, [t]));
+print t;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[1, [...]]\n[2, ([...], [[...]])]\n"
+	if got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+}
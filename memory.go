@@ -0,0 +1,103 @@
+package lox_interpreter
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrOutOfMemory is the cause of the RuntimeError returned when a script allocates more than WithMemoryQuota allows.
+var ErrOutOfMemory = errors.New("out of memory")
+
+// WithMemoryQuota limits the bytes a script allocates for strings, lists, dictionaries, tuples and instance fields.
+// Allocations are counted as values are created and are never given back, so the quota bounds the total
+// a script allocates over its run, not only what it holds at once. Zero means no limit.
+func WithMemoryQuota(bytes int64) Option {
+	return func(i *Interpreter) {
+		i.budget.maxMemory = bytes
+	}
+}
+
+// rough sizes, in bytes, of the Go values behind Lox values.
+const (
+	valueSize        = 16 // an interface{} holding an element
+	stringHeaderSize = 16
+	sliceHeaderSize  = 24
+	mapEntrySize     = 48
+	instanceSize     = 64
+)
+
+// maxStringSize bounds the strings that repeat, `+`, readFile and exec build, also without a quota.
+// Go ends the whole process when an allocation cannot be served, which a script must not be able to cause.
+const maxStringSize = 1 << 30
+
+// sizeOf estimates the bytes allocated to create value, without the values it holds.
+func sizeOf(value interface{}) int64 {
+	switch value := value.(type) {
+	case string:
+		return stringHeaderSize + int64(len(value))
+	case *LoxList:
+		return sliceHeaderSize + valueSize*int64(value.len())
+	case TupleType:
+		return sliceHeaderSize + valueSize*int64(len(value))
+	case *LoxDict:
+		return sliceHeaderSize + mapEntrySize*int64(value.len())
+	case *LoxInstance:
+		return instanceSize
+	}
+
+	return 0
+}
+
+// account counts the memory of a value that was just created, and returns it.
+func (i *Interpreter) account(token Token, value interface{}) (interface{}, error) {
+	if err := i.allocate(token, sizeOf(value)); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// allocate counts bytes against the memory quota, and fails once the quota is exceeded.
+// Spawned functions, timers and async functions share the quota of the interpreter they were forked from.
+func (i *Interpreter) allocate(token Token, bytes int64) error {
+	// bytes is checked on its own first, so that a huge allocation cannot overflow the counter.
+	if max := i.budget.maxMemory; max > 0 && (bytes > max || i.budget.allocated.Add(bytes) > max) {
+		return i.budgetError(token, ErrOutOfMemory)
+	}
+
+	return nil
+}
+
+// readString reads r into a string that is accounted like a created value. It stops reading as soon as the string
+// would exceed the memory left in the quota, or maxStringSize, so a large input is never held in memory at once.
+func (i *Interpreter) readString(token Token, r io.Reader) (string, error) {
+	limit := int64(maxStringSize)
+	quota := false
+	if max := i.budget.maxMemory; max > 0 {
+		if left := max - i.budget.allocated.Load() - stringHeaderSize; left < limit {
+			limit = left
+			quota = true
+		}
+	}
+	if limit < 0 {
+		limit = 0
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return "", err
+	}
+
+	if int64(len(content)) > limit {
+		if quota {
+			return "", i.budgetError(token, ErrOutOfMemory)
+		}
+		return "", NewRuntimeError(token, "String is too large.", i.callStack)
+	}
+
+	if err := i.allocate(token, sizeOf(string(content))); err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
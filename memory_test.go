@@ -0,0 +1,46 @@
+package lox_interpreter_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+func TestReadFileOverQuota(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "big.txt")
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", 1<<20)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	capabilities := lox.Capabilities{ReadRoots: []string{dir}}
+	_, err := runScript(t, `readFile("`+filepath.ToSlash(path)+`");`,
+		lox.WithCapabilities(capabilities), lox.WithMemoryQuota(64<<10))
+	if !errors.Is(err, lox.ErrOutOfMemory) {
+		t.Errorf("got %v, want ErrOutOfMemory", err)
+	}
+
+	got, err := runScript(t, `print len(readFile("`+filepath.ToSlash(path)+`"));`,
+		lox.WithCapabilities(capabilities), lox.WithMemoryQuota(4<<20))
+	if err != nil || got != "1048576\n" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
+
+func TestExecOutputOverQuota(t *testing.T) {
+	if _, err := exec.LookPath("yes"); err != nil {
+		t.Skip("yes is not available")
+	}
+
+	// yes writes forever, so the command has to be stopped once its output exceeds the quota.
+	_, err := runScript(t, `exec("yes", []);`,
+		lox.WithCapabilities(lox.Capabilities{Exec: true}), lox.WithMemoryQuota(64<<10))
+	if !errors.Is(err, lox.ErrOutOfMemory) {
+		t.Errorf("got %v, want ErrOutOfMemory", err)
+	}
+}
@@ -0,0 +1,68 @@
+package lox_interpreter
+
+import (
+	"fmt"
+)
+
+// overloads names the special methods that overload a binary operator. When the left operand does not define
+// method, reflected is tried on the right operand with the operands swapped.
+type overloads struct {
+	method    string
+	reflected string
+}
+
+var binaryOverloads = map[TokenType]overloads{
+	PLUS:          {"__add__", "__radd__"},
+	MINUS:         {"__sub__", "__rsub__"},
+	STAR:          {"__mul__", "__rmul__"},
+	SLASH:         {"__div__", "__rdiv__"},
+	LESS:          {"__lt__", "__gt__"},
+	LESS_EQUAL:    {"__le__", "__ge__"},
+	GREATER:       {"__gt__", "__lt__"},
+	GREATER_EQUAL: {"__ge__", "__le__"},
+}
+
+// specialMethod returns the method called name bound to value, or nil when value is not an instance
+// or its class does not define the method.
+func specialMethod(value interface{}, name string) Callable {
+	instance, ok := value.(*LoxInstance)
+	if !ok {
+		return nil
+	}
+
+	method := instance.class.findMethod(name)
+	if method == nil {
+		return nil
+	}
+
+	return method.Bind(instance)
+}
+
+// overloadBinary calls the special method of an operand that overloads the operator.
+// ok is false when neither operand overloads it.
+func (i *Interpreter) overloadBinary(operator Token, left, right interface{}) (value interface{}, ok bool, err error) {
+	names, ok := binaryOverloads[operator.Type]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if method := specialMethod(left, names.method); method != nil {
+		value, err = i.callSpecialMethod(operator, names.method, method, right)
+		return value, true, err
+	}
+
+	if method := specialMethod(right, names.reflected); method != nil {
+		value, err = i.callSpecialMethod(operator, names.reflected, method, left)
+		return value, true, err
+	}
+
+	return nil, false, nil
+}
+
+func (i *Interpreter) callSpecialMethod(token Token, name string, method Callable, arguments ...interface{}) (interface{}, error) {
+	if method.Arity() != len(arguments) {
+		return nil, NewRuntimeError(token, fmt.Sprintf("Method %s must take %d arguments.", name, len(arguments)), i.callStack)
+	}
+
+	return i.call(method, arguments)
+}
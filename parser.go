@@ -24,14 +24,26 @@ func newParseError(token Token, message string) error {
 program        → declaration* EOF ;
 
 declaration    → varDecl
+               | constDecl
                | funDecl
+               | asyncFunDecl
                | classDecl
+               | traitDecl
+               | enumDecl
                | statement ;
 
-varDecl        → "var" IDENTIFIER ( "=" expression )? ";" ;
+varDecl        → "var" IDENTIFIER ( "=" expression )? ";"
+               | "var" IDENTIFIER ( "," IDENTIFIER )+ "=" expression ";" ;
+constDecl      → "const" IDENTIFIER "=" expression ";" ;
 funDecl        → "fun" function ;
+asyncFunDecl   → "async" "fun" function ;
 function       → IDENTIFIER "(" parameters? ")" block ;
-classDecl      → "class" IDENTIFIER ( "<" IDENTIFIER )? "{" function* "}";
+classDecl      → "class" IDENTIFIER ( "<" IDENTIFIER )?
+                 ( "with" IDENTIFIER ( "," IDENTIFIER )* )? "{" method* "}";
+traitDecl      → "trait" IDENTIFIER "{" method* "}";
+enumDecl       → "enum" IDENTIFIER "{" ( variant ( "," variant )* ","? )? "}" ;
+variant        → IDENTIFIER ( "(" parameters? ")" )? ;
+method         → "async"? function ;
 parameters     → IDENTIFIER ( "," IDENTIFIER )* ;
 
 statement      → exprStmt
@@ -39,7 +51,11 @@ statement      → exprStmt
                | printStmt
                | whileStmt
                | forStmt
+               | forInStmt
                | jumpStmt
+               | yieldStmt
+               | selectStmt
+               | matchStmt
                | block ;
 
 jumpStmt       → breakStmt
@@ -52,14 +68,22 @@ whileStmt      → "while" "(" expression ")" loopStatement ;
 forStmt        → "for" "(" ( varDecl | exprStmt | ";" )
 						   expression? ";"
 						   expression? ")" loopStatement ;
+forInStmt      → "for" "(" "var" IDENTIFIER "in" expression ")" loopStatement ;
 breakStmt      → "break" ";" ;
-returnStmt     → "return" expression? ";" ;
+returnStmt     → "return" ( expression ( "," expression )* )? ";" ;
+yieldStmt      → "yield" expression? ";" ;
+selectStmt     → "select" "{" caseClause* ( "default" block )? "}" ;
+caseClause     → "case" ( "var" IDENTIFIER "=" )? call "." "receive" "(" ")" block
+               | "case" call "." "send" "(" expression ")" block ;
+matchStmt      → "match" "(" expression ")" "{" matchArm* ( "default" block )? "}" ;
+matchArm       → "case" ( call "." IDENTIFIER "(" parameters? ")" | expression ) block ;
 block          → "{" declaration* "}" ;
 
 expression     → assignment ;
 assignment     → ( call "." )? IDENTIFIER "=" assignment
-               | logic_and ;
+               | coalesce ;
 
+coalesce       → logic_or ( "??" logic_or )* ;
 logic_or       → logic_and ( "or" logic_and )* ;
 logic_and      → ternary ( "and" ternary )* ;
 
@@ -69,26 +93,32 @@ equality       → comparison ( ( "!=" | "==" ) comparison )* ;
 comparison     → term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
 term           → factor ( ( "-" | "+" ) factor )* ;
 factor         → unary ( ( "/" | "*" ) unary )* ;
-unary          → ( "!" | "-" ) unary | call ;
-call           → select ( "(" arguments? ")" | "." IDENTIFIER )*;
+unary          → ( "!" | "-" | "await" ) unary | "spawn" call | call ;
+call           → select ( "(" arguments? ")" | "." IDENTIFIER | "[" expression "]"
+               | "?." IDENTIFIER | "?." "(" arguments? ")" | "?" "[" expression "]" )*;
 arguments      → expression ( "," expression )* ;
 select         → primary ( "[" expression "]" )*;
 primary        → NUMBER | STRING | "true" | "false" | "nil"
-               | IDENTIFIER | "(" expression ")" | "super" "." IDENTIFIER
+               | IDENTIFIER | "(" expression ")" | "super" "." IDENTIFIER | tuple
                | dictionary | list ;
-dictionary     → "{" ( IDENTIFIER ":" expression ( "," IDENTIFIER ":" expression )* )? "}" ;
+dictionary     → "{" ( entry ( "," entry )* )? "}" ;
+entry          → ( IDENTIFIER | expression ) ":" expression ;
 list           → "[" ( expression ( "," expression )* )? "]" ;
+tuple          → "(" expression ( "," expression )+ ")" ;
 */
 
 type Parser struct {
-	tokens   []Token
-	current  int
-	isInLoop bool
-	isInFun  []bool
+	config      Config
+	tokens      []Token
+	current     int
+	isInLoop    bool
+	isInFun     []bool
+	isGenerator []bool
 }
 
-func NewParser(tokens []Token) *Parser {
+func NewParser(tokens []Token, config Config) *Parser {
 	return &Parser{
+		config:  config,
 		tokens:  tokens,
 		current: 0,
 	}
@@ -118,8 +148,33 @@ func (p *Parser) Declaration() (Stmt, error) {
 		return stmt, nil
 	}
 
+	if p.match(CONST) {
+		stmt, err := p.constDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+
+		return stmt, nil
+	}
+
 	if p.match(FUN) {
-		stmt, err := p.funDeclaration()
+		stmt, err := p.funDeclaration(false)
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+
+		return stmt, nil
+	}
+
+	if p.match(ASYNC) {
+		err := p.consume(FUN, "Expect 'fun' after 'async'.")
+		if err != nil {
+			return nil, err
+		}
+
+		stmt, err := p.funDeclaration(true)
 		if err != nil {
 			p.synchronize()
 			return nil, err
@@ -138,8 +193,29 @@ func (p *Parser) Declaration() (Stmt, error) {
 		return stmt, nil
 	}
 
+	if p.match(TRAIT) {
+		stmt, err := p.traitDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+
+		return stmt, nil
+	}
+
+	if p.match(ENUM) {
+		stmt, err := p.enumDeclaration()
+		if err != nil {
+			p.synchronize()
+			return nil, err
+		}
+
+		return stmt, nil
+	}
+
 	stmt, err := p.Statement()
 	if err != nil {
+		p.synchronize()
 		return nil, err
 	}
 
@@ -152,6 +228,10 @@ func (p *Parser) varDeclaration() (Stmt, error) {
 		return nil, err
 	}
 
+	if p.check(COMMA) {
+		return p.unpackDeclaration(identifier)
+	}
+
 	var initializer Expr
 	if p.match(EQUAL) {
 		initializer, err = p.Expression()
@@ -168,10 +248,69 @@ func (p *Parser) varDeclaration() (Stmt, error) {
 	return NewVarStmt(identifier, initializer), nil
 }
 
-func (p *Parser) funDeclaration() (Stmt, error) {
+// unpackDeclaration parses the rest of `var q, r = divmod(7, 2);` after the first name.
+func (p *Parser) unpackDeclaration(first Token) (Stmt, error) {
+	names := []Token{first}
+	for p.match(COMMA) {
+		identifier, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, identifier)
+	}
+
+	err := p.consume(EQUAL, "Expect '=' after variable names.")
+	if err != nil {
+		return nil, err
+	}
+	equals := p.previous()
+
+	initializer, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(SEMICOLON, "Expect ';' after variable declaration.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewUnpackStmt(names, equals, initializer), nil
+}
+
+func (p *Parser) constDeclaration() (Stmt, error) {
+	identifier, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(EQUAL, "Expect '=' after constant name.")
+	if err != nil {
+		return nil, err
+	}
+
+	initializer, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(SEMICOLON, "Expect ';' after constant declaration.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConstStmt(identifier, initializer), nil
+}
+
+func (p *Parser) funDeclaration(isAsync bool) (Stmt, error) {
 	p.isInFun = append(p.isInFun, true)
 	defer func() { p.isInFun = p.isInFun[:len(p.isInFun)-1] }()
 
+	// a function becomes a generator when `yield` appears directly in its body.
+	p.isGenerator = append(p.isGenerator, false)
+	defer func() { p.isGenerator = p.isGenerator[:len(p.isGenerator)-1] }()
+
 	identifier, err := p.identifier()
 	if err != nil {
 		return nil, err
@@ -202,7 +341,7 @@ func (p *Parser) funDeclaration() (Stmt, error) {
 		return nil, err
 	}
 
-	return NewFunStmt(identifier, parameters, block), nil
+	return NewFunStmt(identifier, parameters, block, p.isGenerator[len(p.isGenerator)-1], isAsync), nil
 }
 
 func (p *Parser) classDeclaration() (Stmt, error) {
@@ -221,14 +360,106 @@ func (p *Parser) classDeclaration() (Stmt, error) {
 		superclass = NewVariableExpr(spc)
 	}
 
+	var traits []*VariableExpr
+	if p.match(WITH) {
+		for {
+			trait, err := p.identifier()
+			if err != nil {
+				return nil, err
+			}
+
+			traits = append(traits, NewVariableExpr(trait))
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
 	err = p.consume(LEFT_BRACE, "Expect '{' after class name.")
 	if err != nil {
 		return nil, err
 	}
 
+	methods, err := p.methods("Expect '}' after class body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClassStmt(identifier, superclass, traits, methods), nil
+}
+
+func (p *Parser) traitDeclaration() (Stmt, error) {
+	identifier, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(LEFT_BRACE, "Expect '{' after trait name.")
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := p.methods("Expect '}' after trait body.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTraitStmt(identifier, methods), nil
+}
+
+func (p *Parser) enumDeclaration() (Stmt, error) {
+	identifier, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(LEFT_BRACE, "Expect '{' after enum name.")
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []Token
+	var fields [][]Token
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		variant, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+
+		var parameters []Token
+		if p.match(LEFT_PAREN) {
+			parameters, err = p.parameters()
+			if err != nil {
+				return nil, err
+			}
+
+			err = p.consume(RIGHT_PAREN, "Expect ')' after variant fields.")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		variants = append(variants, variant)
+		fields = append(fields, parameters)
+
+		if !p.match(COMMA) {
+			break
+		}
+	}
+
+	err = p.consume(RIGHT_BRACE, "Expect '}' after enum variants.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEnumStmt(identifier, variants, fields), nil
+}
+
+func (p *Parser) methods(message string) ([]*FunStmt, error) {
 	var methods []*FunStmt
 	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
-		method, err := p.funDeclaration()
+		method, err := p.funDeclaration(p.match(ASYNC))
 		if err != nil {
 			return nil, err
 		}
@@ -236,12 +467,12 @@ func (p *Parser) classDeclaration() (Stmt, error) {
 		methods = append(methods, method.(*FunStmt))
 	}
 
-	err = p.consume(RIGHT_BRACE, "Expect '}' after class body.")
+	err := p.consume(RIGHT_BRACE, message)
 	if err != nil {
 		return nil, err
 	}
 
-	return NewClassStmt(identifier, superclass, methods), nil
+	return methods, nil
 }
 
 func (p *Parser) parameters() ([]Token, error) {
@@ -306,12 +537,21 @@ func (p *Parser) Statement() (Stmt, error) {
 		return p.breakStatement()
 	}
 	if p.match(RETURN) {
-		if NO_RETURN_AT_ROOT && len(p.isInFun) == 0 {
+		if !p.config.ReturnAtRoot && len(p.isInFun) == 0 {
 			return nil, newParseError(p.previous(), "Expect return statement inside function.")
 		}
 
 		return p.returnStatement()
 	}
+	if p.match(YIELD) {
+		return p.yieldStatement()
+	}
+	if p.match(SELECT) {
+		return p.selectStatement()
+	}
+	if p.match(MATCH) {
+		return p.matchStatement()
+	}
 
 	return p.expressionStatement()
 }
@@ -359,6 +599,10 @@ func (p *Parser) forStatement() (Stmt, error) {
 		return nil, err
 	}
 
+	if p.check(VAR) && p.peekNext(2).Type == IN {
+		return p.forInStatement()
+	}
+
 	var initializer Stmt
 	if p.match(VAR) {
 		initializer, err = p.varDeclaration()
@@ -409,7 +653,11 @@ func (p *Parser) forStatement() (Stmt, error) {
 		return nil, err
 	}
 
-	whileStatement := NewWhileStmt(condition, NewBlockStmt([]Stmt{body, NewExpressionStmt(increment)}))
+	if increment != nil {
+		body = NewBlockStmt([]Stmt{body, NewExpressionStmt(increment)})
+	}
+
+	whileStatement := NewWhileStmt(condition, body)
 	if initializer != nil {
 		return NewBlockStmt([]Stmt{initializer, whileStatement}), nil
 	}
@@ -417,6 +665,37 @@ func (p *Parser) forStatement() (Stmt, error) {
 	return whileStatement, nil
 }
 
+// `for (var x in xs) foo(x);` runs the body once for every element of xs.
+func (p *Parser) forInStatement() (Stmt, error) {
+	p.advance()
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(IN, "Expect 'in' after loop variable.")
+	if err != nil {
+		return nil, err
+	}
+
+	iterable, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(RIGHT_PAREN, "Expect ')' after for clauses.")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.Statement()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewForInStmt(name, iterable, body), nil
+}
+
 func (p *Parser) breakStatement() (Stmt, error) {
 	breakToken := p.previous()
 
@@ -437,6 +716,13 @@ func (p *Parser) returnStatement() (stmt Stmt, err error) {
 		if err != nil {
 			return nil, err
 		}
+
+		if p.check(COMMA) {
+			value, err = p.tuple(returnToken, value)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	err = p.consume(SEMICOLON, "Expect ';' after return value.")
@@ -447,7 +733,227 @@ func (p *Parser) returnStatement() (stmt Stmt, err error) {
 	return NewReturnStmt(returnToken, value), nil
 }
 
+func (p *Parser) yieldStatement() (stmt Stmt, err error) {
+	yieldToken := p.previous()
+	if len(p.isGenerator) > 0 {
+		p.isGenerator[len(p.isGenerator)-1] = true
+	}
+
+	var value Expr
+	if !p.check(SEMICOLON) {
+		value, err = p.Expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = p.consume(SEMICOLON, "Expect ';' after yield value.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewYieldStmt(yieldToken, value), nil
+}
+
+func (p *Parser) selectStatement() (Stmt, error) {
+	keyword := p.previous()
+	err := p.consume(LEFT_BRACE, "Expect '{' after 'select'.")
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []*CaseStmt
+	for p.match(CASE) {
+		c, err := p.caseClause()
+		if err != nil {
+			return nil, err
+		}
+
+		cases = append(cases, c)
+	}
+
+	var defaultBranch Stmt
+	if p.match(DEFAULT) {
+		err = p.consume(LEFT_BRACE, "Expect '{' after 'default'.")
+		if err != nil {
+			return nil, err
+		}
+
+		statements, err := p.blockStatement()
+		if err != nil {
+			return nil, err
+		}
+		defaultBranch = NewBlockStmt(statements)
+	}
+
+	err = p.consume(RIGHT_BRACE, "Expect '}' after select cases.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSelectStmt(keyword, cases, defaultBranch), nil
+}
+
+// caseClause parses `case var v = ch.receive() { ... }` or `case ch.send(value) { ... }`.
+func (p *Parser) caseClause() (*CaseStmt, error) {
+	keyword := p.previous()
+
+	var name Token
+	if p.match(VAR) {
+		identifier, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		name = identifier
+
+		err = p.consume(EQUAL, "Expect '=' after variable name.")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	expr, err := p.call()
+	if err != nil {
+		return nil, err
+	}
+
+	call, ok := expr.(*CallExpr)
+	if !ok {
+		return nil, newParseError(keyword, "Expect channel operation after 'case'.")
+	}
+
+	get, ok := call.callee.(*GetExpr)
+	if !ok || (get.name.Lexeme != "send" && get.name.Lexeme != "receive") {
+		return nil, newParseError(keyword, "Expect channel operation after 'case'.")
+	}
+
+	var value Expr
+	switch get.name.Lexeme {
+	case "send":
+		if len(call.arguments) != 1 {
+			return nil, newParseError(call.paren, "Expect one value to send.")
+		}
+		value = call.arguments[0]
+	case "receive":
+		if len(call.arguments) != 0 {
+			return nil, newParseError(call.paren, "Expect no arguments to receive.")
+		}
+	}
+
+	if name.Lexeme != "" && get.name.Lexeme == "send" {
+		return nil, newParseError(name, "Cannot assign the result of send.")
+	}
+
+	err = p.consume(LEFT_BRACE, "Expect '{' after case.")
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := p.blockStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCaseStmt(keyword, name, get.object, get.name, value, NewBlockStmt(statements)), nil
+}
+
+func (p *Parser) matchStatement() (Stmt, error) {
+	keyword := p.previous()
+	err := p.consume(LEFT_PAREN, "Expect '(' after 'match'.")
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(RIGHT_PAREN, "Expect ')' after match value.")
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(LEFT_BRACE, "Expect '{' after match value.")
+	if err != nil {
+		return nil, err
+	}
+
+	var arms []*MatchArmStmt
+	for p.match(CASE) {
+		arm, err := p.matchArm()
+		if err != nil {
+			return nil, err
+		}
+
+		arms = append(arms, arm)
+	}
+
+	var defaultBranch Stmt
+	if p.match(DEFAULT) {
+		err = p.consume(LEFT_BRACE, "Expect '{' after 'default'.")
+		if err != nil {
+			return nil, err
+		}
+
+		statements, err := p.blockStatement()
+		if err != nil {
+			return nil, err
+		}
+		defaultBranch = NewBlockStmt(statements)
+	}
+
+	err = p.consume(RIGHT_BRACE, "Expect '}' after match arms.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMatchStmt(keyword, value, arms, defaultBranch), nil
+}
+
+// matchArm parses `case Result.Ok(value) { ... }`, which destructures an enum value,
+// or `case expression { ... }`, which compares the matched value with the expression.
+func (p *Parser) matchArm() (*MatchArmStmt, error) {
+	keyword := p.previous()
+
+	pattern, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+
+	var variant Token
+	var bindings []Token
+	if call, ok := pattern.(*CallExpr); ok {
+		if get, ok := call.callee.(*GetExpr); ok {
+			for _, argument := range call.arguments {
+				binding, ok := argument.(*VariableExpr)
+				if !ok {
+					return nil, newParseError(call.paren, "Expect variable names in variant pattern.")
+				}
+
+				bindings = append(bindings, binding.name)
+			}
+
+			pattern = get
+			variant = get.name
+		}
+	}
+
+	err = p.consume(LEFT_BRACE, "Expect '{' after case pattern.")
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := p.blockStatement()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMatchArmStmt(keyword, pattern, variant, bindings, NewBlockStmt(statements)), nil
+}
+
 func (p *Parser) printStatement() (Stmt, error) {
+	keyword := p.previous()
 	expr, err := p.Expression()
 	if err != nil {
 		return nil, err
@@ -458,7 +964,7 @@ func (p *Parser) printStatement() (Stmt, error) {
 		return nil, err
 	}
 
-	return NewPrintStmt(expr), nil
+	return NewPrintStmt(keyword, expr), nil
 }
 
 func (p *Parser) ifStatement() (Stmt, error) {
@@ -527,7 +1033,7 @@ func (p *Parser) Expression() (Expr, error) {
 }
 
 func (p *Parser) assignment() (Expr, error) {
-	expr, err := p.or()
+	expr, err := p.coalesce()
 	if err != nil {
 		return nil, err
 	}
@@ -551,6 +1057,25 @@ func (p *Parser) assignment() (Expr, error) {
 	return expr, nil
 }
 
+func (p *Parser) coalesce() (Expr, error) {
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(QUESTION_QUESTION) {
+		operator := p.previous()
+		right, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = NewLogicalExpr(expr, operator, right)
+	}
+
+	return expr, nil
+}
+
 func (p *Parser) or() (Expr, error) {
 	expr, err := p.and()
 	if err != nil {
@@ -747,29 +1272,147 @@ func (p *Parser) unary() (Expr, error) {
 		return NewUnaryExpr(token, right), nil
 	}
 
+	if p.match(AWAIT) {
+		keyword := p.previous()
+		value, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return NewAwaitExpr(keyword, value), nil
+	}
+
+	if p.match(SPAWN) {
+		keyword := p.previous()
+		expr, err := p.call()
+		if err != nil {
+			return nil, err
+		}
+
+		call, ok := expr.(*CallExpr)
+		if !ok {
+			return nil, newParseError(keyword, "Expect function call after 'spawn'.")
+		}
+
+		return NewSpawnExpr(keyword, call), nil
+	}
+
 	return p.call()
 }
 
+// isOptionalIndex reports whether the current `?` followed by `[` starts an optional index, like `xs?[0]`,
+// and not a conditional, like `c ?[1] : [2]`. It is a conditional when more `:` follow than conditionals
+// before it are waiting for, counting only tokens of the same expression.
+func (p *Parser) isOptionalIndex() bool {
+	if !p.check(QUESTION) || p.peekNext(1).Type != LEFT_BRACKET {
+		return false
+	}
+
+	// conditionals before the `?` that have not seen their `:` yet.
+	waiting, colons, depth := 0, 0, 0
+	for n := p.current - 1; n >= 0 && depth >= 0; n-- {
+		switch p.tokens[n].Type {
+		case RIGHT_PAREN, RIGHT_BRACKET, RIGHT_BRACE:
+			depth++
+		case LEFT_PAREN, LEFT_BRACKET, LEFT_BRACE:
+			depth--
+		case SEMICOLON, COMMA:
+			if depth == 0 {
+				depth = -1
+			}
+		case COLON:
+			if depth == 0 {
+				colons++
+			}
+		case QUESTION:
+			// an earlier `?[` is more likely an optional index than a conditional.
+			if depth == 0 && p.tokens[n+1].Type != LEFT_BRACKET {
+				if colons > 0 {
+					colons--
+				} else {
+					waiting++
+				}
+			}
+		}
+	}
+
+	// `:` after the `?` that no conditional after it takes.
+	unclaimed, questions, depth := 0, 0, 0
+	for n := p.current + 1; n < len(p.tokens) && depth >= 0; n++ {
+		switch p.tokens[n].Type {
+		case LEFT_PAREN, LEFT_BRACKET, LEFT_BRACE:
+			depth++
+		case RIGHT_PAREN, RIGHT_BRACKET, RIGHT_BRACE:
+			depth--
+		case SEMICOLON, COMMA, EOF:
+			if depth == 0 {
+				depth = -1
+			}
+		case QUESTION:
+			if depth == 0 {
+				questions++
+			}
+		case COLON:
+			if depth == 0 {
+				if questions > 0 {
+					questions--
+				} else {
+					unclaimed++
+				}
+			}
+		}
+	}
+
+	return unclaimed <= waiting
+}
+
 func (p *Parser) call() (Expr, error) {
 	expr, err := p._select()
 	if err != nil {
 		return nil, err
 	}
 
+	// isOptional is set when the chain has `?.` or `?[`, so that a nil in the middle short-circuits the whole chain.
+	isOptional := false
 	for {
-		if p.match(LEFT_PAREN) {
-			var arguments []Expr
+		if p.match(QUESTION_DOT) {
+			isOptional = true
+			expr = NewOptionalExpr(expr, p.previous())
+
+			if p.match(LEFT_PAREN) {
+				expr, err = p.finishCall(expr)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
 
-			arguments, err = p.arguments()
+			name, err := p.identifier()
 			if err != nil {
 				return nil, err
 			}
 
-			err = p.consume(RIGHT_PAREN, "Expect ')' after arguments.")
+			expr = NewGetExpr(expr, name)
+		} else if p.isOptionalIndex() {
+			p.advance()
+			isOptional = true
+			expr = NewOptionalExpr(expr, p.previous())
+
+			p.advance()
+			expr, err = p.finishIndex(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(LEFT_PAREN) {
+			expr, err = p.finishCall(expr)
+			if err != nil {
+				return nil, err
+			}
+		} else if p.match(LEFT_BRACKET) {
+			expr, err = p.finishIndex(expr)
 			if err != nil {
 				return nil, err
 			}
-			expr = NewCallExpr(expr, p.previous(), arguments)
 		} else if p.match(DOT) {
 			name, err := p.identifier()
 			if err != nil {
@@ -782,9 +1425,41 @@ func (p *Parser) call() (Expr, error) {
 		}
 	}
 
+	if isOptional {
+		return NewChainExpr(expr), nil
+	}
+
 	return expr, nil
 }
 
+func (p *Parser) finishCall(callee Expr) (Expr, error) {
+	arguments, err := p.arguments()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(RIGHT_PAREN, "Expect ')' after arguments.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCallExpr(callee, p.previous(), arguments), nil
+}
+
+func (p *Parser) finishIndex(object Expr) (Expr, error) {
+	index, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.consume(RIGHT_BRACKET, "Expect ']' after index.")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSelectExpr(object, index), nil
+}
+
 func (p *Parser) arguments() (arguments []Expr, err error) {
 	for {
 		if len(arguments) >= 255 {
@@ -816,21 +1491,10 @@ func (p *Parser) _select() (Expr, error) {
 		return nil, err
 	}
 
-	for {
-		if p.match(LEFT_BRACKET) {
-			index, err := p.Expression()
-			if err != nil {
-				return nil, err
-			}
-
-			err = p.consume(RIGHT_BRACKET, "Expect ']' after index.")
-			if err != nil {
-				return nil, err
-			}
-
-			expr = NewSelectExpr(expr, index)
-		} else {
-			break
+	for p.match(LEFT_BRACKET) {
+		expr, err = p.finishIndex(expr)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -853,11 +1517,25 @@ func (p *Parser) primary() (Expr, error) {
 	}
 
 	if p.match(LEFT_PAREN) {
+		paren := p.previous()
 		expr, err := p.Expression()
 		if err != nil {
 			return nil, err
 		}
 
+		if p.check(COMMA) {
+			tuple, err := p.tuple(paren, expr)
+			if err != nil {
+				return nil, err
+			}
+
+			err = p.consume(RIGHT_PAREN, "Expect ')' after tuple.")
+			if err != nil {
+				return nil, err
+			}
+			return tuple, nil
+		}
+
 		err = p.consume(RIGHT_PAREN, "Expect ')' after expression.")
 		if err != nil {
 			return nil, err
@@ -897,15 +1575,37 @@ func (p *Parser) primary() (Expr, error) {
 	return nil, newParseError(p.peek(), "Expect expression.")
 }
 
-func (p *Parser) dictionary() (Expr, error) {
-	dict := make(map[Token]Expr)
-	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
-		key, err := p.identifier()
+// tuple parses the comma separated expressions that follow first.
+func (p *Parser) tuple(token Token, first Expr) (Expr, error) {
+	values := []Expr{first}
+	for p.match(COMMA) {
+		value, err := p.Expression()
 		if err != nil {
 			return nil, err
 		}
 
-		err = p.consume(COLON, "Expect ':' after key.")
+		values = append(values, value)
+	}
+
+	return NewTupleExpr(token, values), nil
+}
+
+func (p *Parser) dictionary() (Expr, error) {
+	brace := p.previous()
+	var keys, values []Expr
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		var key Expr
+		if p.check(IDENTIFIER) && p.peekNext(1).Type == COLON {
+			key = NewLiteralExpr(p.advance().Lexeme)
+		} else {
+			expr, err := p.Expression()
+			if err != nil {
+				return nil, err
+			}
+			key = expr
+		}
+
+		err := p.consume(COLON, "Expect ':' after key.")
 		if err != nil {
 			return nil, err
 		}
@@ -915,7 +1615,8 @@ func (p *Parser) dictionary() (Expr, error) {
 			return nil, err
 		}
 
-		dict[key] = value
+		keys = append(keys, key)
+		values = append(values, value)
 
 		if !p.match(COMMA) {
 			break
@@ -927,7 +1628,7 @@ func (p *Parser) dictionary() (Expr, error) {
 		return nil, err
 	}
 
-	return NewDictionaryExpr(dict), nil
+	return NewDictionaryExpr(brace, keys, values), nil
 }
 
 func (p *Parser) list() (Expr, error) {
@@ -1004,6 +1705,14 @@ func (p *Parser) peek() Token {
 	return p.tokens[p.current]
 }
 
+func (p *Parser) peekNext(n int) Token {
+	if p.current+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+
+	return p.tokens[p.current+n]
+}
+
 func (p *Parser) synchronize() {
 	p.advance()
 
@@ -1013,23 +1722,7 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().Type {
-		case CLASS:
-			continue
-		case FUN:
-			continue
-		case VAR:
-			continue
-		case FOR:
-			continue
-		case IF:
-			continue
-		case WHILE:
-			continue
-		case PRINT:
-			continue
-		case RETURN:
-			return
-		case BREAK:
+		case CLASS, TRAIT, ENUM, FUN, ASYNC, VAR, CONST, FOR, IF, WHILE, PRINT, RETURN, BREAK:
 			return
 		}
 
@@ -0,0 +1,29 @@
+package lox_interpreter_test
+
+import "testing"
+
+func TestOptionalIndexAndConditional(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"var xs = [10, 20]; print xs?[1];", "20\n"},
+		{"var xs = nil; print xs?[0] ?? \"none\";", "none\n"},
+		{"var c = true; print c ?[1] : [2];", "[1]\n"},
+		{"var c = false; print c ?[1] : [2];", "[2]\n"},
+		{"var c = true; var xs = [10]; print c ? xs?[0] : 0;", "10\n"},
+		{"var c = true; var xs = [10]; print c ?[xs?[0]] : [2];", "[10]\n"},
+		{"var c = true; var xs = [10]; print {k: xs?[0], j: c ?[1] : [2]};", "{k: 10, j: [1]}\n"},
+	}
+
+	for _, test := range tests {
+		got, err := runScript(t, test.source)
+		if err != nil {
+			t.Errorf("%s: %v", test.source, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: printed %q, want %q", test.source, got, test.want)
+		}
+	}
+}
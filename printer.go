@@ -24,6 +24,26 @@ func (ap *AstPrinter) VisitSelectExpr(expr *SelectExpr) (interface{}, error) {
 	panic("implement me")
 }
 
+func (ap *AstPrinter) VisitOptionalExpr(expr *OptionalExpr) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitChainExpr(expr *ChainExpr) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitSpawnExpr(expr *SpawnExpr) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitAwaitExpr(expr *AwaitExpr) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
 func (ap *AstPrinter) VisitSuperExpr(expr *SuperExpr) (interface{}, error) {
 	//TODO implement me
 	panic("implement me")
@@ -49,6 +69,11 @@ func (ap *AstPrinter) VisitClassStmt(expr *ClassStmt) (interface{}, error) {
 	panic("implement me")
 }
 
+func (ap *AstPrinter) VisitTraitStmt(expr *TraitStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
 func (ap *AstPrinter) VisitReturnStmt(expr *ReturnStmt) (interface{}, error) {
 	//TODO implement me
 	panic("implement me")
@@ -86,6 +111,15 @@ func (ap *AstPrinter) VisitVarStmt(stmt *VarStmt) (interface{}, error) {
 	return statementString, nil
 }
 
+func (ap *AstPrinter) VisitConstStmt(stmt *ConstStmt) (interface{}, error) {
+	d, err := ap.parenthesize("=", stmt.initializer)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("const (%s%s)", stmt.name, toString(d)), nil
+}
+
 func (ap *AstPrinter) VisitExpressionStmt(expr *ExpressionStmt) (interface{}, error) {
 	return expr.expression.Accept(ap)
 }
@@ -99,6 +133,51 @@ func (ap *AstPrinter) VisitWhileStmt(expr *WhileStmt) (interface{}, error) {
 	panic("implement me")
 }
 
+func (ap *AstPrinter) VisitForInStmt(expr *ForInStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitYieldStmt(expr *YieldStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitSelectStmt(expr *SelectStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitCaseStmt(expr *CaseStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitEnumStmt(expr *EnumStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitMatchStmt(expr *MatchStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitMatchArmStmt(expr *MatchArmStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitUnpackStmt(expr *UnpackStmt) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
+func (ap *AstPrinter) VisitTupleExpr(expr *TupleExpr) (interface{}, error) {
+	//TODO implement me
+	panic("implement me")
+}
+
 func (ap *AstPrinter) VisitBreakStmt(expr *BreakStmt) (interface{}, error) {
 	//TODO implement me
 	panic("implement me")
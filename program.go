@@ -0,0 +1,103 @@
+package lox_interpreter
+
+import "fmt"
+
+// Diagnostic is a problem that Compile found in a script.
+type Diagnostic struct {
+	Line    int
+	Message string
+	// Err is the error of the scanner, parser or resolver that reported the problem.
+	Err error
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("[line %d] %s", d.Line, d.Message)
+}
+
+func newDiagnostic(err error) Diagnostic {
+	var line int
+	switch err := err.(type) {
+	case *ParseError:
+		line = err.Token.LineNumber
+	case *CompileError:
+		line = err.token.LineNumber
+	}
+
+	return Diagnostic{line, err.Error(), err}
+}
+
+// Program is a parsed and resolved script. It is never changed after Compile,
+// so one Program can be run many times, also concurrently, each time by another interpreter.
+type Program struct {
+	config     Config
+	statements []Stmt
+	locals     map[Expr]int
+}
+
+// Compile scans, parses and resolves source with the switches of config. The script may use the native functions
+// and the given globals, which the host defines with RegisterFunc or BindValue before running the program.
+// Compile returns no Program when it finds any problem.
+func Compile(source string, config Config, globals ...string) (*Program, []Diagnostic) {
+	scanner := NewScanner(source)
+	tokens := scanner.scan()
+	if len(scanner.diagnostics) > 0 {
+		return nil, scanner.diagnostics
+	}
+
+	var diagnostics []Diagnostic
+	var statements []Stmt
+	parser := NewParser(tokens, config)
+	for !parser.isAtEnd() {
+		// Declaration skips to the next statement after an error, so the problems of every statement are reported.
+		stmt, err := parser.Declaration()
+		if err != nil {
+			diagnostics = append(diagnostics, newDiagnostic(err))
+			continue
+		}
+		statements = append(statements, stmt)
+	}
+	if len(diagnostics) > 0 {
+		return nil, diagnostics
+	}
+
+	env := NewEnvironment(nil)
+	defineNatives(env)
+	for _, name := range globals {
+		env.Define(name, nil)
+	}
+
+	program := &Program{
+		config:     config,
+		statements: statements,
+		locals:     make(map[Expr]int),
+	}
+	resolver := newResolver(env, program.locals, config)
+	for _, stmt := range statements {
+		if err := resolver.ResolveStatements(stmt); err != nil {
+			diagnostics = append(diagnostics, newDiagnostic(err))
+		}
+	}
+	if len(diagnostics) > 0 {
+		return nil, diagnostics
+	}
+
+	// resolving no statements only checks that every local variable is used.
+	if err := resolver.Resolve(); err != nil {
+		return nil, []Diagnostic{newDiagnostic(err)}
+	}
+
+	return program, nil
+}
+
+// Run runs program in the globals of the interpreter, which should be a fresh one with the Config of the program.
+// The interpreter looks up variables in the resolution of the program without copying or changing it.
+// Tasks and timers that a previous run left behind must be finished before the interpreter runs another program.
+func (i *Interpreter) Run(program *Program) (interface{}, error) {
+	if i.config != program.config {
+		return nil, fmt.Errorf("program and interpreter have different configs")
+	}
+
+	i.programs = append(i.programs, program.locals)
+
+	return i.Interpret(program.statements)
+}
@@ -0,0 +1,293 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var (
+	errorType       = reflect.TypeOf((*error)(nil)).Elem()
+	interpreterType = reflect.TypeOf((*Interpreter)(nil))
+)
+
+// RegisterFunc defines a global function called name that calls fn, which must be a Go function.
+// Arguments are converted from Lox values to the parameter types of fn, and results back to Lox values:
+// numbers become any integer or float type, lists and tuples become slices, and dictionaries become maps.
+// Pointers to structs are passed to scripts as objects, like BindValue does, and back as the same pointer.
+// Parameters of type interface{} or Callable get the Lox value as it is.
+//
+// fn may take the *Interpreter as its first parameter. If its last result is an error, a non-nil error
+// becomes a runtime error of the script. Several other results are returned as a tuple.
+func (i *Interpreter) RegisterFunc(name string, fn interface{}) error {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return fmt.Errorf("cannot register %s: %T is not a function", name, fn)
+	}
+
+	native, err := newGoFunction(name, value)
+	if err != nil {
+		return fmt.Errorf("cannot register %s: %w", name, err)
+	}
+
+	i.Globals.Define(name, native)
+	return nil
+}
+
+// newGoFunction wraps value, which must be a function, in a NativeFunction that converts its arguments and results.
+func newGoFunction(name string, value reflect.Value) (*NativeFunction, error) {
+	t := value.Type()
+	if t.IsVariadic() {
+		return nil, fmt.Errorf("variadic functions are not supported")
+	}
+
+	var params []reflect.Type
+	takesInterpreter := t.NumIn() > 0 && t.In(0) == interpreterType
+	for p := 0; p < t.NumIn(); p++ {
+		if p == 0 && takesInterpreter {
+			continue
+		}
+		params = append(params, t.In(p))
+	}
+
+	returnsError := t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+
+	return NewNativeFunction(name, len(params), func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+		var in []reflect.Value
+		if takesInterpreter {
+			in = append(in, reflect.ValueOf(interpreter))
+		}
+
+		for p, param := range params {
+			argument, err := toGo(arguments[p], param)
+			if err != nil {
+				return nil, NewRuntimeError(Token{}, fmt.Sprintf("Argument %d of %s %s.", p+1, name, err.Error()), interpreter.callStack)
+			}
+			in = append(in, argument)
+		}
+
+		out, err := callGo(interpreter, name, value, in)
+		if err != nil {
+			return nil, err
+		}
+
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, &RuntimeError{Token{}, err.Error(), append([]Callable(nil), interpreter.callStack...), err}
+			}
+			out = out[:len(out)-1]
+		}
+
+		switch len(out) {
+		case 0:
+			return nil, nil
+		case 1:
+			return interpreter.account(Token{}, fromGo(out[0]))
+		}
+
+		results := make(TupleType, len(out))
+		for r, result := range out {
+			results[r] = fromGo(result)
+		}
+		return interpreter.account(Token{}, results)
+	}), nil
+}
+
+// callGo calls fn with in. A panic of fn becomes a RuntimeError, which is reported at the call of the script.
+func callGo(interpreter *Interpreter, name string, fn reflect.Value, in []reflect.Value) (out []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = NewRuntimeError(Token{}, fmt.Sprintf("%s panicked: %v", name, r), interpreter.callStack)
+		}
+	}()
+
+	return fn.Call(in), nil
+}
+
+// toGo converts a Lox value to a Go value of type t. The error completes the sentence "Argument 1 of fn ...".
+func toGo(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("must not be nil")
+	}
+
+	if g, ok := value.(*LoxGoValue); ok {
+		switch {
+		case g.value.Type().AssignableTo(t):
+			return g.value, nil
+		case g.value.Elem().Type().AssignableTo(t):
+			return g.value.Elem(), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot be converted to %s", t)
+	}
+
+	if reflect.TypeOf(value).AssignableTo(t) {
+		return reflect.ValueOf(value), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return reflect.Value{}, fmt.Errorf("must be an integer")
+		}
+
+		converted := reflect.New(t).Elem()
+		if converted.OverflowInt(int64(n)) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return reflect.Value{}, fmt.Errorf("is out of range: %s", Stringify(n))
+		}
+		converted.SetInt(int64(n))
+		return converted, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return reflect.Value{}, fmt.Errorf("must be an integer")
+		}
+
+		converted := reflect.New(t).Elem()
+		if n < 0 || n >= math.MaxUint64 || converted.OverflowUint(uint64(n)) {
+			return reflect.Value{}, fmt.Errorf("is out of range: %s", Stringify(n))
+		}
+		converted.SetUint(uint64(n))
+		return converted, nil
+	case reflect.Float32, reflect.Float64:
+		n, ok := value.(float64)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a number")
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a string")
+		}
+		return reflect.ValueOf(s).Convert(t), nil
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a boolean")
+		}
+		return reflect.ValueOf(b).Convert(t), nil
+	case reflect.Slice:
+		var elements []interface{}
+		switch value := value.(type) {
+		case *LoxList:
+			elements = value.snapshot()
+		case TupleType:
+			elements = value
+		default:
+			return reflect.Value{}, fmt.Errorf("must be a list")
+		}
+
+		converted := reflect.MakeSlice(t, len(elements), len(elements))
+		for e, element := range elements {
+			c, err := toGo(element, t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("has an element that %s", err.Error())
+			}
+			converted.Index(e).Set(c)
+		}
+		return converted, nil
+	case reflect.Map:
+		dict, ok := value.(*LoxDict)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("must be a dictionary")
+		}
+
+		keys, values := dict.entries()
+		converted := reflect.MakeMapWithSize(t, len(keys))
+		for e := range keys {
+			key, err := toGo(keys[e], t.Key())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("has a key that %s", err.Error())
+			}
+
+			element, err := toGo(values[e], t.Elem())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("has a value that %s", err.Error())
+			}
+			converted.SetMapIndex(key, element)
+		}
+		return converted, nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("cannot be converted to %s", t)
+}
+
+// fromGo converts a Go value to a Lox value. Values that have no Lox counterpart are returned as they are.
+func fromGo(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return value.Bool()
+	case reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return fromGo(value.Elem())
+	case reflect.Ptr:
+		if value.IsNil() {
+			return nil
+		}
+
+		if t := value.Type().Elem(); t.Kind() == reflect.Struct && t.PkgPath() != loxPackage {
+			return newLoxGoValue(value)
+		}
+	case reflect.Struct:
+		if value.Type().PkgPath() == loxPackage {
+			break
+		}
+
+		// fields of a wrapped struct stay connected to it, other structs are copied.
+		if value.CanAddr() {
+			return newLoxGoValue(value.Addr())
+		}
+
+		copied := reflect.New(value.Type())
+		copied.Elem().Set(value)
+		return newLoxGoValue(copied)
+	case reflect.Func:
+		if value.IsNil() {
+			return nil
+		}
+	case reflect.Slice, reflect.Array:
+		if value.Type() == reflect.TypeOf(TupleType(nil)) {
+			break
+		}
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+
+		elements := make([]interface{}, value.Len())
+		for e := range elements {
+			elements[e] = fromGo(value.Index(e))
+		}
+		return NewLoxList(elements)
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+
+		dict := NewLoxDict()
+		iter := value.MapRange()
+		for iter.Next() {
+			// keys that cannot be dictionary keys are left out.
+			_ = dict.Set(fromGo(iter.Key()), fromGo(iter.Value()))
+		}
+		return dict
+	}
+
+	return value.Interface()
+}
@@ -0,0 +1,39 @@
+package lox_interpreter_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	lox "github.com/ariyn/lox_interpreter"
+)
+
+func TestRegisteredFunctionPanics(t *testing.T) {
+	var out bytes.Buffer
+	interpreter := lox.NewInterpreter(nil, lox.WithStdout(&out))
+	err := interpreter.RegisterFunc("explode", func(n int) int {
+		var values []int
+		return values[n]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	program, diagnostics := lox.Compile("print \"before\";\nexplode(3);\nprint \"after\";", lox.Config{}, "explode")
+	if diagnostics != nil {
+		t.Fatal(diagnostics)
+	}
+
+	_, err = interpreter.Run(program)
+	var runtimeError *lox.RuntimeError
+	if !errors.As(err, &runtimeError) {
+		t.Fatalf("got %v, want a RuntimeError", err)
+	}
+	if !strings.HasPrefix(err.Error(), "2 at ')' explode panicked: runtime error: index out of range") {
+		t.Errorf("got %q", err.Error())
+	}
+	if out.String() != "before\n" {
+		t.Errorf("printed %q", out.String())
+	}
+}
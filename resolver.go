@@ -4,8 +4,6 @@ import (
 	"fmt"
 )
 
-var NO_RETURN_AT_ROOT = true
-
 type FunctionType string
 
 const (
@@ -18,9 +16,10 @@ const (
 type ClassType string
 
 const (
-	NONE_CLASS ClassType = "NONE_CLASS"
-	CLS        ClassType = "CLASS"
-	SUBCLASS   ClassType = "SUBCLASS"
+	NONE_CLASS  ClassType = "NONE_CLASS"
+	CLS         ClassType = "CLASS"
+	SUBCLASS    ClassType = "SUBCLASS"
+	TRAIT_CLASS ClassType = "TRAIT"
 )
 
 type CompileError struct {
@@ -40,25 +39,42 @@ var _ ExprVisitor = (*Resolver)(nil)
 var _ StmtVisitor = (*Resolver)(nil)
 
 type Resolver struct {
-	interpreter      *Interpreter
+	config           Config
+	locals           map[Expr]int
 	scope            []map[string]bool
+	constants        []map[string]bool
 	currentFunction  FunctionType
 	currentClass     ClassType
 	isCurrentlyClass bool
+	isCurrentlyAsync bool
+	// traits holds the traits declared in each scope, so that mixins are checked against the trait they refer to.
+	traits []map[string]*TraitStmt
 }
 
 func NewResolver(interpreter *Interpreter) *Resolver {
+	return newResolver(interpreter.Env, interpreter.localsTable, interpreter.config)
+}
+
+// newResolver creates a resolver for a program that runs in globals, which writes the depth of local variables into locals.
+func newResolver(globals *Environment, locals map[Expr]int, config Config) *Resolver {
 	scope := make([]map[string]bool, 0)
 	scope = append(scope, make(map[string]bool))
-	for k := range interpreter.Env.Values {
+	constants := make([]map[string]bool, 0)
+	constants = append(constants, make(map[string]bool))
+	for k := range globals.Values {
 		scope[len(scope)-1][k] = true
+		constants[len(constants)-1][k] = globals.IsConstant(k)
 	}
 
 	return &Resolver{
-		interpreter:      interpreter,
+		config:           config,
+		locals:           locals,
 		scope:            scope,
+		constants:        constants,
 		currentFunction:  NONE,
+		currentClass:     NONE_CLASS,
 		isCurrentlyClass: false,
+		traits:           []map[string]*TraitStmt{make(map[string]*TraitStmt)},
 	}
 }
 
@@ -80,6 +96,43 @@ func (r *Resolver) VisitVarStmt(stmt *VarStmt) (_ interface{}, err error) {
 	return
 }
 
+func (r *Resolver) VisitUnpackStmt(stmt *UnpackStmt) (_ interface{}, err error) {
+	for _, name := range stmt.names {
+		err = r.declare(name)
+		if err != nil {
+			return
+		}
+	}
+
+	err = r.ResolveExpressions(stmt.initializer)
+	if err != nil {
+		return
+	}
+
+	for _, name := range stmt.names {
+		r.define(name)
+	}
+
+	return
+}
+
+func (r *Resolver) VisitConstStmt(stmt *ConstStmt) (_ interface{}, err error) {
+	err = r.declare(stmt.name)
+	if err != nil {
+		return
+	}
+
+	err = r.ResolveExpressions(stmt.initializer)
+	if err != nil {
+		return
+	}
+
+	r.define(stmt.name)
+	r.constants[len(r.constants)-1][stmt.name.Lexeme] = true
+
+	return
+}
+
 func (r *Resolver) declare(name Token) (err error) {
 	scope := r.scope[len(r.scope)-1]
 	if _, ok := scope[name.Lexeme]; ok {
@@ -108,9 +161,12 @@ func (r *Resolver) VisitFunStmt(stmt *FunStmt) (_ interface{}, err error) {
 
 func (r *Resolver) VisitClassStmt(expr *ClassStmt) (_ interface{}, err error) {
 	isCurrentlyClass := r.isCurrentlyClass
+	enclosingClass := r.currentClass
 	r.isCurrentlyClass = true
+	r.currentClass = CLS
 	defer func() {
 		r.isCurrentlyClass = isCurrentlyClass
+		r.currentClass = enclosingClass
 	}()
 
 	err = r.declare(expr.name)
@@ -132,6 +188,22 @@ func (r *Resolver) VisitClassStmt(expr *ClassStmt) (_ interface{}, err error) {
 		}
 	}
 
+	for _, trait := range expr.traits {
+		if expr.name.Lexeme == trait.name.Lexeme {
+			return nil, NewCompileError(trait.name, "A class cannot mix in itself.")
+		}
+
+		err = r.ResolveExpressions(trait)
+		if err != nil {
+			return
+		}
+	}
+
+	err = r.checkTraitConflicts(expr)
+	if err != nil {
+		return
+	}
+
 	if expr.superClass != nil {
 		r.beginScope()
 		defer r.endScope()
@@ -157,6 +229,116 @@ func (r *Resolver) VisitClassStmt(expr *ClassStmt) (_ interface{}, err error) {
 	return nil, nil
 }
 
+// checkTraitConflicts reports methods that come from more than one trait and are not overridden by the class.
+func (r *Resolver) checkTraitConflicts(expr *ClassStmt) error {
+	overridden := make(map[string]bool)
+	for _, method := range expr.methods {
+		overridden[method.name.Lexeme] = true
+	}
+
+	traitOf := make(map[string]Token)
+	for _, t := range expr.traits {
+		trait := r.lookupTrait(t.name)
+		if trait == nil {
+			continue
+		}
+
+		for _, method := range trait.methods {
+			name := method.name.Lexeme
+			if overridden[name] {
+				continue
+			}
+
+			if other, ok := traitOf[name]; ok {
+				return NewCompileError(t.name, fmt.Sprintf("Method '%s' is defined in both trait '%s' and trait '%s'.", name, other.Lexeme, t.name.Lexeme))
+			}
+			traitOf[name] = t.name
+		}
+	}
+
+	return nil
+}
+
+// lookupTrait returns the trait that name refers to, or nil when the nearest declaration of name is not a trait.
+func (r *Resolver) lookupTrait(name Token) *TraitStmt {
+	for s := len(r.scope) - 1; s >= 0; s-- {
+		if _, ok := r.scope[s][name.Lexeme]; ok {
+			return r.traits[s][name.Lexeme]
+		}
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitTraitStmt(stmt *TraitStmt) (_ interface{}, err error) {
+	isCurrentlyClass := r.isCurrentlyClass
+	enclosingClass := r.currentClass
+	r.isCurrentlyClass = true
+	r.currentClass = TRAIT_CLASS
+	defer func() {
+		r.isCurrentlyClass = isCurrentlyClass
+		r.currentClass = enclosingClass
+	}()
+
+	err = r.declare(stmt.name)
+	if err != nil {
+		return
+	}
+
+	r.define(stmt.name)
+	r.traits[len(r.traits)-1][stmt.name.Lexeme] = stmt
+
+	// trait methods always get a `super` scope, which is bound to the superclass of the class they are mixed into.
+	r.beginScope()
+	defer r.endScope()
+	r.scope[len(r.scope)-1]["super"] = true
+
+	r.beginScope()
+	defer r.endScope()
+	r.scope[len(r.scope)-1]["this"] = true
+
+	for _, method := range stmt.methods {
+		functionType := METHOD
+		if method.name.Lexeme == "init" {
+			functionType = INITIALIZER
+		}
+
+		err = r.resolveFunction(method, functionType)
+		if err != nil {
+			return
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *Resolver) VisitEnumStmt(stmt *EnumStmt) (_ interface{}, err error) {
+	err = r.declare(stmt.name)
+	if err != nil {
+		return
+	}
+
+	r.define(stmt.name)
+
+	variants := make(map[string]bool)
+	for i, variant := range stmt.variants {
+		if variants[variant.Lexeme] {
+			return nil, NewCompileError(variant, fmt.Sprintf("Duplicate variant '%s' in enum %s.", variant.Lexeme, stmt.name.Lexeme))
+		}
+		variants[variant.Lexeme] = true
+
+		fields := make(map[string]bool)
+		for _, field := range stmt.fields[i] {
+			if fields[field.Lexeme] {
+				return nil, NewCompileError(field, fmt.Sprintf("Duplicate field '%s' in variant %s.", field.Lexeme, variant.Lexeme))
+			}
+			fields[field.Lexeme] = true
+		}
+	}
+
+	return nil, nil
+}
+
 func (r *Resolver) VisitExpressionStmt(expr *ExpressionStmt) (interface{}, error) {
 	return nil, r.ResolveExpressions(expr.expression)
 }
@@ -191,12 +373,31 @@ func (r *Resolver) VisitWhileStmt(expr *WhileStmt) (_ interface{}, err error) {
 	return
 }
 
+func (r *Resolver) VisitForInStmt(stmt *ForInStmt) (_ interface{}, err error) {
+	err = r.ResolveExpressions(stmt.iterable)
+	if err != nil {
+		return
+	}
+
+	r.beginScope()
+	defer r.endScope()
+
+	err = r.declare(stmt.name)
+	if err != nil {
+		return
+	}
+	r.define(stmt.name)
+
+	err = r.ResolveStatements(stmt.body)
+	return
+}
+
 func (r *Resolver) VisitBreakStmt(expr *BreakStmt) (_ interface{}, err error) {
 	return
 }
 
 func (r *Resolver) VisitReturnStmt(expr *ReturnStmt) (_ interface{}, err error) {
-	if NO_RETURN_AT_ROOT && r.currentFunction == NONE {
+	if !r.config.ReturnAtRoot && r.currentFunction == NONE {
 		return nil, NewCompileError(expr.keyword, "Cannot return from top-level code.")
 	}
 	if r.currentFunction == INITIALIZER {
@@ -210,6 +411,102 @@ func (r *Resolver) VisitReturnStmt(expr *ReturnStmt) (_ interface{}, err error)
 	return
 }
 
+func (r *Resolver) VisitYieldStmt(stmt *YieldStmt) (_ interface{}, err error) {
+	if r.currentFunction == NONE {
+		return nil, NewCompileError(stmt.keyword, "Cannot yield from top-level code.")
+	}
+	if r.currentFunction == INITIALIZER {
+		return nil, NewCompileError(stmt.keyword, "Cannot yield from an initializer.")
+	}
+	if r.isCurrentlyAsync {
+		return nil, NewCompileError(stmt.keyword, "Cannot yield from an async function.")
+	}
+
+	if stmt.value != nil {
+		err = r.ResolveExpressions(stmt.value)
+	}
+
+	return
+}
+
+func (r *Resolver) VisitSelectStmt(stmt *SelectStmt) (_ interface{}, err error) {
+	for _, c := range stmt.cases {
+		_, err = c.Accept(r)
+		if err != nil {
+			return
+		}
+	}
+
+	err = r.ResolveStatements(stmt.defaultBranch)
+	return
+}
+
+func (r *Resolver) VisitMatchStmt(stmt *MatchStmt) (_ interface{}, err error) {
+	err = r.ResolveExpressions(stmt.value)
+	if err != nil {
+		return
+	}
+
+	for _, arm := range stmt.arms {
+		_, err = arm.Accept(r)
+		if err != nil {
+			return
+		}
+	}
+
+	err = r.ResolveStatements(stmt.defaultBranch)
+	return
+}
+
+func (r *Resolver) VisitMatchArmStmt(stmt *MatchArmStmt) (_ interface{}, err error) {
+	err = r.ResolveExpressions(stmt.pattern)
+	if err != nil {
+		return
+	}
+
+	r.beginScope()
+	defer r.endScope()
+
+	for _, binding := range stmt.bindings {
+		err = r.declare(binding)
+		if err != nil {
+			return
+		}
+		r.define(binding)
+	}
+
+	err = r.ResolveStatements(stmt.body)
+	return
+}
+
+func (r *Resolver) VisitCaseStmt(stmt *CaseStmt) (_ interface{}, err error) {
+	err = r.ResolveExpressions(stmt.channel)
+	if err != nil {
+		return
+	}
+
+	if stmt.value != nil {
+		err = r.ResolveExpressions(stmt.value)
+		if err != nil {
+			return
+		}
+	}
+
+	r.beginScope()
+	defer r.endScope()
+
+	if stmt.name.Lexeme != "" {
+		err = r.declare(stmt.name)
+		if err != nil {
+			return
+		}
+		r.define(stmt.name)
+	}
+
+	err = r.ResolveStatements(stmt.body)
+	return
+}
+
 func (r *Resolver) VisitBlockStmt(stmt *BlockStmt) (_ interface{}, err error) {
 	r.beginScope()
 	defer r.endScope()
@@ -224,6 +521,15 @@ func (r *Resolver) VisitAssignExpr(expr *AssignExpr) (_ interface{}, err error)
 		return
 	}
 
+	for i := len(r.scope) - 1; i >= 0; i-- {
+		if _, ok := r.scope[i][expr.name.Lexeme]; ok {
+			if r.constants[i][expr.name.Lexeme] {
+				return nil, NewCompileError(expr.name, fmt.Sprintf("Cannot assign to constant '%s'.", expr.name.Lexeme))
+			}
+			break
+		}
+	}
+
 	err = r.resolveLocal(expr, expr.name)
 	return
 }
@@ -326,7 +632,7 @@ func (r *Resolver) VisitThisExpr(expr *ThisExpr) (_ interface{}, err error) {
 func (r *Resolver) VisitSuperExpr(expr *SuperExpr) (interface{}, error) {
 	if r.currentClass == NONE_CLASS {
 		return nil, NewCompileError(expr.keyword, "Cannot use 'super' outside of a class.")
-	} else if r.currentClass != SUBCLASS {
+	} else if r.currentClass != SUBCLASS && r.currentClass != TRAIT_CLASS {
 		return nil, NewCompileError(expr.keyword, "Cannot use 'super' in a class with no superclass.")
 	}
 	err := r.resolveLocal(expr, expr.keyword)
@@ -338,20 +644,22 @@ func (r *Resolver) VisitSuperExpr(expr *SuperExpr) (interface{}, error) {
 }
 
 func (r *Resolver) VisitDictionaryExpr(expr *DictionaryExpr) (interface{}, error) {
-	dict := make(map[string]Expr)
-	for k, v := range expr.mapExpr {
-		err := r.ResolveExpressions(v)
+	literals := make(map[interface{}]bool)
+	for n := range expr.keys {
+		err := r.ResolveExpressions(expr.keys[n], expr.values[n])
 		if err != nil {
 			return nil, err
 		}
 
-		if _, ok := dict[k.Lexeme]; ok {
-			return nil, NewCompileError(k, "Duplicate key in dictionary.")
+		if key, ok := expr.keys[n].(*LiteralExpr); ok {
+			if literals[key.value] {
+				return nil, NewCompileError(expr.brace, "Duplicate key in dictionary.")
+			}
+			literals[key.value] = true
 		}
-		dict[k.Lexeme] = v
 	}
 
-	return dict, nil
+	return nil, nil
 }
 
 func (r *Resolver) VisitSelectExpr(expr *SelectExpr) (interface{}, error) {
@@ -360,10 +668,6 @@ func (r *Resolver) VisitSelectExpr(expr *SelectExpr) (interface{}, error) {
 		return nil, err
 	}
 
-	if _, ok := expr.object.(*VariableExpr); !ok {
-		return nil, NewCompileError(Token{}, "Only variable can have properties.")
-	}
-
 	err = r.ResolveExpressions(expr.name)
 	if err != nil {
 		return nil, err
@@ -376,10 +680,30 @@ func (r *Resolver) VisitListExpr(expr *ListExpr) (interface{}, error) {
 	return nil, r.ResolveExpressions(expr.values...)
 }
 
+func (r *Resolver) VisitTupleExpr(expr *TupleExpr) (interface{}, error) {
+	return nil, r.ResolveExpressions(expr.values...)
+}
+
+func (r *Resolver) VisitOptionalExpr(expr *OptionalExpr) (interface{}, error) {
+	return nil, r.ResolveExpressions(expr.object)
+}
+
+func (r *Resolver) VisitChainExpr(expr *ChainExpr) (interface{}, error) {
+	return nil, r.ResolveExpressions(expr.expression)
+}
+
+func (r *Resolver) VisitSpawnExpr(expr *SpawnExpr) (interface{}, error) {
+	return nil, r.ResolveExpressions(expr.call)
+}
+
+func (r *Resolver) VisitAwaitExpr(expr *AwaitExpr) (interface{}, error) {
+	return nil, r.ResolveExpressions(expr.value)
+}
+
 func (r *Resolver) resolveLocal(expr Expr, name Token) (err error) {
 	for i := len(r.scope) - 1; i >= 0; i-- {
 		if _, ok := r.scope[i][name.Lexeme]; ok {
-			_, err = r.interpreter.ResolveExpression(expr, len(r.scope)-1-i)
+			r.locals[expr] = len(r.scope) - 1 - i
 			return
 		}
 	}
@@ -388,10 +712,17 @@ func (r *Resolver) resolveLocal(expr Expr, name Token) (err error) {
 }
 
 func (r *Resolver) resolveFunction(stmt *FunStmt, functionType FunctionType) (err error) {
+	if functionType == INITIALIZER && stmt.isAsync {
+		return NewCompileError(stmt.name, "An initializer cannot be async.")
+	}
+
 	enclosingFunction := r.currentFunction
+	isCurrentlyAsync := r.isCurrentlyAsync
 	r.currentFunction = functionType
+	r.isCurrentlyAsync = stmt.isAsync
 	defer func() {
 		r.currentFunction = enclosingFunction
+		r.isCurrentlyAsync = isCurrentlyAsync
 	}()
 
 	r.beginScope()
@@ -412,14 +743,22 @@ func (r *Resolver) resolveFunction(stmt *FunStmt, functionType FunctionType) (er
 
 func (r *Resolver) beginScope() {
 	r.scope = append(r.scope, make(map[string]bool))
+	r.constants = append(r.constants, make(map[string]bool))
+	r.traits = append(r.traits, make(map[string]*TraitStmt))
 }
 
 func (r *Resolver) endScope() {
 	r.scope = r.scope[:len(r.scope)-1]
+	r.constants = r.constants[:len(r.constants)-1]
+	r.traits = r.traits[:len(r.traits)-1]
 }
 
 func (r *Resolver) ResolveStatements(statements ...Stmt) (err error) {
 	for _, stmt := range statements {
+		if stmt == nil {
+			continue
+		}
+
 		_, err = stmt.Accept(r)
 		if err != nil {
 			return
@@ -0,0 +1,87 @@
+package lox_interpreter
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPermissionDenied is wrapped by the errors of natives that are called without the capability they need.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Capabilities lists what the natives of an interpreter may do. The zero value allows nothing.
+type Capabilities struct {
+	// ReadRoots and WriteRoots are the directories whose files can be read and written.
+	ReadRoots  []string
+	WriteRoots []string
+	Exec       bool
+	Env        bool
+	Clock      bool
+	Random     bool
+}
+
+// DefaultCapabilities returns the capabilities of an interpreter created without WithCapabilities.
+// Only the clock is allowed, so scripts can not reach the host unless the embedder allows it.
+func DefaultCapabilities() Capabilities {
+	return Capabilities{Clock: true}
+}
+
+// WithCapabilities sets what the natives of the interpreter may do.
+func WithCapabilities(capabilities Capabilities) Option {
+	return func(i *Interpreter) {
+		i.capabilities = capabilities
+	}
+}
+
+func permissionDenied(format string, arguments ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrPermissionDenied, fmt.Sprintf(format, arguments...))
+}
+
+// allowedPath resolves path and reports whether it is inside one of roots.
+// Symbolic links are resolved first, so a link inside a root can not point outside of it.
+func allowedPath(path string, roots []string) (string, bool) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", false
+	}
+
+	for _, root := range roots {
+		resolvedRoot, err := resolvePath(root)
+		if err != nil {
+			continue
+		}
+
+		rel, err := filepath.Rel(resolvedRoot, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, true
+		}
+	}
+
+	return "", false
+}
+
+// resolvePath makes path absolute and resolves the symbolic links of the part of it that exists.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+
+	dir, file := filepath.Split(abs)
+	dir = filepath.Clean(dir)
+	if dir == abs {
+		return abs, nil
+	}
+
+	resolvedDir, err := resolvePath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(resolvedDir, file), nil
+}
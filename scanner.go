@@ -10,9 +10,10 @@ type Scanner struct {
 	Source string
 	Tokens []Token
 
-	start   int
-	current int
-	line    int
+	start       int
+	current     int
+	line        int
+	diagnostics []Diagnostic
 }
 
 func NewScanner(source string) *Scanner {
@@ -26,14 +27,24 @@ func NewScanner(source string) *Scanner {
 	}
 }
 
+// ScanTokens scans the source and logs every error it finds. The last error is returned.
 func (s *Scanner) ScanTokens() (tokens []Token, err error) {
+	tokens = s.scan()
+	for _, diagnostic := range s.diagnostics {
+		log.Println(diagnostic.Err.Error())
+		err = diagnostic.Err
+	}
+
+	return tokens, err
+}
+
+// scan scans the source and keeps the errors it finds in diagnostics.
+func (s *Scanner) scan() []Token {
 	for !s.isAtEnd() {
 		s.start = s.current
 
-		_err := s.scanToken()
-		if _err != nil {
-			log.Println(_err.Error())
-			err = _err
+		if err := s.scanToken(); err != nil {
+			s.diagnostics = append(s.diagnostics, Diagnostic{s.line, err.Error(), err})
 		}
 	}
 
@@ -44,7 +55,7 @@ func (s *Scanner) ScanTokens() (tokens []Token, err error) {
 		s.line,
 	})
 
-	return s.Tokens, err
+	return s.Tokens
 }
 
 func (s *Scanner) isAtEnd() bool {
@@ -67,7 +78,13 @@ func (s *Scanner) scanToken() error {
 	case "]":
 		s.addToken(RIGHT_BRACKET, nil)
 	case "?":
-		s.addToken(QUESTION, nil)
+		typ := QUESTION
+		if s.match(".") {
+			typ = QUESTION_DOT
+		} else if s.match("?") {
+			typ = QUESTION_QUESTION
+		}
+		s.addToken(typ, nil)
 	case ",":
 		s.addToken(COMMA, nil)
 	case ".":
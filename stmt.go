@@ -2,16 +2,30 @@ package lox_interpreter
 
 type StmtVisitor interface {
 	VisitVarStmt(expr *VarStmt) (interface{}, error)
+	VisitConstStmt(expr *ConstStmt) (interface{}, error)
+	VisitUnpackStmt(expr *UnpackStmt) (interface{}, error)
 	VisitFunStmt(expr *FunStmt) (interface{}, error)
 	VisitExpressionStmt(expr *ExpressionStmt) (interface{}, error)
 	VisitIfStmt(expr *IfStmt) (interface{}, error)
 	VisitPrintStmt(expr *PrintStmt) (interface{}, error)
 	VisitWhileStmt(expr *WhileStmt) (interface{}, error)
+	VisitForInStmt(expr *ForInStmt) (interface{}, error)
 	VisitBreakStmt(expr *BreakStmt) (interface{}, error)
 	VisitReturnStmt(expr *ReturnStmt) (interface{}, error)
+	VisitYieldStmt(expr *YieldStmt) (interface{}, error)
 	VisitBlockStmt(expr *BlockStmt) (interface{}, error)
 	VisitClassStmt(expr *ClassStmt) (interface{}, error)
+	VisitTraitStmt(expr *TraitStmt) (interface{}, error)
+	VisitSelectStmt(expr *SelectStmt) (interface{}, error)
+	VisitCaseStmt(expr *CaseStmt) (interface{}, error)
+	VisitEnumStmt(expr *EnumStmt) (interface{}, error)
+	VisitMatchStmt(expr *MatchStmt) (interface{}, error)
+	VisitMatchArmStmt(expr *MatchArmStmt) (interface{}, error)
 }
+
+// Stmt is a node of the syntax tree. Nodes are never changed after parsing: the resolver and the interpreter
+// keep their state in their own structures, so one tree can be run by interpreters on several goroutines at once.
+// TestConcurrentRuns checks this with the race detector.
 type Stmt interface {
 	Accept(v StmtVisitor) (interface{}, error)
 }
@@ -34,19 +48,61 @@ func (e *VarStmt) Accept(v StmtVisitor) (interface{}, error) {
 	return v.VisitVarStmt(e)
 }
 
+var _ Stmt = (*ConstStmt)(nil)
+
+type ConstStmt struct {
+	name        Token
+	initializer Expr
+}
+
+func NewConstStmt(name Token, initializer Expr) *ConstStmt {
+	return &ConstStmt{
+		name,
+		initializer,
+	}
+}
+
+func (e *ConstStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitConstStmt(e)
+}
+
+var _ Stmt = (*UnpackStmt)(nil)
+
+type UnpackStmt struct {
+	names       []Token
+	equals      Token
+	initializer Expr
+}
+
+func NewUnpackStmt(names []Token, equals Token, initializer Expr) *UnpackStmt {
+	return &UnpackStmt{
+		names,
+		equals,
+		initializer,
+	}
+}
+
+func (e *UnpackStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitUnpackStmt(e)
+}
+
 var _ Stmt = (*FunStmt)(nil)
 
 type FunStmt struct {
-	name   Token
-	params []Token
-	body   []Stmt
+	name        Token
+	params      []Token
+	body        []Stmt
+	isGenerator bool
+	isAsync     bool
 }
 
-func NewFunStmt(name Token, params []Token, body []Stmt) *FunStmt {
+func NewFunStmt(name Token, params []Token, body []Stmt, isGenerator bool, isAsync bool) *FunStmt {
 	return &FunStmt{
 		name,
 		params,
 		body,
+		isGenerator,
+		isAsync,
 	}
 }
 
@@ -93,11 +149,13 @@ func (e *IfStmt) Accept(v StmtVisitor) (interface{}, error) {
 var _ Stmt = (*PrintStmt)(nil)
 
 type PrintStmt struct {
+	keyword    Token
 	expression Expr
 }
 
-func NewPrintStmt(expression Expr) *PrintStmt {
+func NewPrintStmt(keyword Token, expression Expr) *PrintStmt {
 	return &PrintStmt{
+		keyword,
 		expression,
 	}
 }
@@ -124,6 +182,26 @@ func (e *WhileStmt) Accept(v StmtVisitor) (interface{}, error) {
 	return v.VisitWhileStmt(e)
 }
 
+var _ Stmt = (*ForInStmt)(nil)
+
+type ForInStmt struct {
+	name     Token
+	iterable Expr
+	body     Stmt
+}
+
+func NewForInStmt(name Token, iterable Expr, body Stmt) *ForInStmt {
+	return &ForInStmt{
+		name,
+		iterable,
+		body,
+	}
+}
+
+func (e *ForInStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitForInStmt(e)
+}
+
 var _ Stmt = (*BreakStmt)(nil)
 
 type BreakStmt struct {
@@ -158,6 +236,24 @@ func (e *ReturnStmt) Accept(v StmtVisitor) (interface{}, error) {
 	return v.VisitReturnStmt(e)
 }
 
+var _ Stmt = (*YieldStmt)(nil)
+
+type YieldStmt struct {
+	keyword Token
+	value   Expr
+}
+
+func NewYieldStmt(keyword Token, value Expr) *YieldStmt {
+	return &YieldStmt{
+		keyword,
+		value,
+	}
+}
+
+func (e *YieldStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitYieldStmt(e)
+}
+
 var _ Stmt = (*BlockStmt)(nil)
 
 type BlockStmt struct {
@@ -179,13 +275,15 @@ var _ Stmt = (*ClassStmt)(nil)
 type ClassStmt struct {
 	name       Token
 	superClass *VariableExpr
+	traits     []*VariableExpr
 	methods    []*FunStmt
 }
 
-func NewClassStmt(name Token, superClass *VariableExpr, methods []*FunStmt) *ClassStmt {
+func NewClassStmt(name Token, superClass *VariableExpr, traits []*VariableExpr, methods []*FunStmt) *ClassStmt {
 	return &ClassStmt{
 		name,
 		superClass,
+		traits,
 		methods,
 	}
 }
@@ -193,3 +291,133 @@ func NewClassStmt(name Token, superClass *VariableExpr, methods []*FunStmt) *Cla
 func (e *ClassStmt) Accept(v StmtVisitor) (interface{}, error) {
 	return v.VisitClassStmt(e)
 }
+
+var _ Stmt = (*TraitStmt)(nil)
+
+type TraitStmt struct {
+	name    Token
+	methods []*FunStmt
+}
+
+func NewTraitStmt(name Token, methods []*FunStmt) *TraitStmt {
+	return &TraitStmt{
+		name,
+		methods,
+	}
+}
+
+func (e *TraitStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitTraitStmt(e)
+}
+
+var _ Stmt = (*SelectStmt)(nil)
+
+type SelectStmt struct {
+	keyword       Token
+	cases         []*CaseStmt
+	defaultBranch Stmt
+}
+
+func NewSelectStmt(keyword Token, cases []*CaseStmt, defaultBranch Stmt) *SelectStmt {
+	return &SelectStmt{
+		keyword,
+		cases,
+		defaultBranch,
+	}
+}
+
+func (e *SelectStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitSelectStmt(e)
+}
+
+var _ Stmt = (*CaseStmt)(nil)
+
+type CaseStmt struct {
+	keyword   Token
+	name      Token
+	channel   Expr
+	operation Token
+	value     Expr
+	body      Stmt
+}
+
+func NewCaseStmt(keyword Token, name Token, channel Expr, operation Token, value Expr, body Stmt) *CaseStmt {
+	return &CaseStmt{
+		keyword,
+		name,
+		channel,
+		operation,
+		value,
+		body,
+	}
+}
+
+func (e *CaseStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitCaseStmt(e)
+}
+
+var _ Stmt = (*EnumStmt)(nil)
+
+type EnumStmt struct {
+	name     Token
+	variants []Token
+	fields   [][]Token
+}
+
+func NewEnumStmt(name Token, variants []Token, fields [][]Token) *EnumStmt {
+	return &EnumStmt{
+		name,
+		variants,
+		fields,
+	}
+}
+
+func (e *EnumStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitEnumStmt(e)
+}
+
+var _ Stmt = (*MatchStmt)(nil)
+
+type MatchStmt struct {
+	keyword       Token
+	value         Expr
+	arms          []*MatchArmStmt
+	defaultBranch Stmt
+}
+
+func NewMatchStmt(keyword Token, value Expr, arms []*MatchArmStmt, defaultBranch Stmt) *MatchStmt {
+	return &MatchStmt{
+		keyword,
+		value,
+		arms,
+		defaultBranch,
+	}
+}
+
+func (e *MatchStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitMatchStmt(e)
+}
+
+var _ Stmt = (*MatchArmStmt)(nil)
+
+type MatchArmStmt struct {
+	keyword  Token
+	pattern  Expr
+	variant  Token
+	bindings []Token
+	body     Stmt
+}
+
+func NewMatchArmStmt(keyword Token, pattern Expr, variant Token, bindings []Token, body Stmt) *MatchArmStmt {
+	return &MatchArmStmt{
+		keyword,
+		pattern,
+		variant,
+		bindings,
+		body,
+	}
+}
+
+func (e *MatchArmStmt) Accept(v StmtVisitor) (interface{}, error) {
+	return v.VisitMatchArmStmt(e)
+}
@@ -0,0 +1,130 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringMethod is a native method of strings. fn is called with the receiver and the arguments of the call.
+// The memory of the result is accounted after fn returns.
+type stringMethod struct {
+	arity int
+	fn    func(interpreter *Interpreter, receiver string, arguments []interface{}) (interface{}, error)
+}
+
+var stringMethods = map[string]stringMethod{
+	"upper": {0, func(_ *Interpreter, receiver string, _ []interface{}) (interface{}, error) {
+		return strings.ToUpper(receiver), nil
+	}},
+	"lower": {0, func(_ *Interpreter, receiver string, _ []interface{}) (interface{}, error) {
+		return strings.ToLower(receiver), nil
+	}},
+	"trim": {0, func(_ *Interpreter, receiver string, _ []interface{}) (interface{}, error) {
+		return strings.TrimSpace(receiver), nil
+	}},
+	"split": {1, func(_ *Interpreter, receiver string, arguments []interface{}) (interface{}, error) {
+		sep, err := stringArgument("split", arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		var parts []interface{}
+		for _, part := range strings.Split(receiver, sep) {
+			parts = append(parts, part)
+		}
+		return NewLoxList(parts), nil
+	}},
+	"replace": {2, func(_ *Interpreter, receiver string, arguments []interface{}) (interface{}, error) {
+		old, err := stringArgument("replace", arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		replacement, err := stringArgument("replace", arguments[1])
+		if err != nil {
+			return nil, err
+		}
+
+		return strings.ReplaceAll(receiver, old, replacement), nil
+	}},
+	"startsWith": {1, func(_ *Interpreter, receiver string, arguments []interface{}) (interface{}, error) {
+		prefix, err := stringArgument("startsWith", arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return strings.HasPrefix(receiver, prefix), nil
+	}},
+	"contains": {1, func(_ *Interpreter, receiver string, arguments []interface{}) (interface{}, error) {
+		substr, err := stringArgument("contains", arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return strings.Contains(receiver, substr), nil
+	}},
+	// find returns the index of the first character of substr, counted in characters and not bytes, or -1.
+	"find": {1, func(_ *Interpreter, receiver string, arguments []interface{}) (interface{}, error) {
+		substr, err := stringArgument("find", arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		index := strings.Index(receiver, substr)
+		if index < 0 {
+			return float64(-1), nil
+		}
+		return float64(utf8.RuneCountInString(receiver[:index])), nil
+	}},
+	"repeat": {1, func(interpreter *Interpreter, receiver string, arguments []interface{}) (interface{}, error) {
+		count, ok := arguments[0].(float64)
+		if !ok || count < 0 || count != float64(int(count)) {
+			return nil, fmt.Errorf("Argument of repeat must be a non-negative integer.")
+		}
+
+		// the size is checked before the result is built, because it can be far larger than the receiver.
+		if len(receiver) > 0 && count > float64(maxStringSize/len(receiver)) {
+			return nil, NewRuntimeError(Token{}, "Result of repeat is too large.", interpreter.callStack)
+		}
+
+		if err := interpreter.allocate(Token{}, int64(len(receiver))*int64(count)); err != nil {
+			return nil, err
+		}
+
+		return strings.Repeat(receiver, int(count)), nil
+	}},
+	"chars": {0, func(_ *Interpreter, receiver string, _ []interface{}) (interface{}, error) {
+		var chars []interface{}
+		for _, c := range receiver {
+			chars = append(chars, string(c))
+		}
+		return NewLoxList(chars), nil
+	}},
+}
+
+// getStringMethod returns the method called name, bound to receiver.
+func getStringMethod(receiver string, name Token) (interface{}, error) {
+	method, ok := stringMethods[name.Lexeme]
+	if !ok {
+		return nil, NewEnvironmentError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+	}
+
+	return NewNativeFunction(name.Lexeme, method.arity, func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+		result, err := method.fn(interpreter, receiver, arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		return result, interpreter.allocate(name, sizeOf(result))
+	}), nil
+}
+
+func stringArgument(method string, argument interface{}) (string, error) {
+	s, ok := argument.(string)
+	if !ok {
+		return "", fmt.Errorf("Argument of %s must be a string.", method)
+	}
+
+	return s, nil
+}
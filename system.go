@@ -0,0 +1,221 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/exec"
+)
+
+var _ Callable = (*ReadFile)(nil)
+
+// ReadFile returns the content of a file inside one of the read roots of the interpreter.
+type ReadFile struct{}
+
+func (n ReadFile) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	path, err := stringArgument("readFile", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, ok := allowedPath(path, interpreter.capabilities.ReadRoots)
+	if !ok {
+		return nil, permissionDenied("cannot read '%s'", path)
+	}
+
+	file, err := os.Open(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read '%s': %v", path, err)
+	}
+	defer file.Close()
+
+	content, err := interpreter.readString(Token{}, file)
+	if _, ok := err.(*RuntimeError); ok {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("Cannot read '%s': %v", path, err)
+	}
+
+	return content, nil
+}
+
+func (n ReadFile) Arity() int {
+	return 1
+}
+
+func (n ReadFile) ToString() string {
+	return "<native fn readFile>"
+}
+
+func (n ReadFile) Bind(instance *LoxInstance) Callable {
+	return n
+}
+
+var _ Callable = (*WriteFile)(nil)
+
+// WriteFile writes a string to a file inside one of the write roots of the interpreter, replacing its content.
+type WriteFile struct{}
+
+func (n WriteFile) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	path, err := stringArgument("writeFile", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := stringArgument("writeFile", arguments[1])
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, ok := allowedPath(path, interpreter.capabilities.WriteRoots)
+	if !ok {
+		return nil, permissionDenied("cannot write '%s'", path)
+	}
+
+	if err := os.WriteFile(resolved, []byte(content), 0o644); err != nil {
+		return nil, fmt.Errorf("Cannot write '%s': %v", path, err)
+	}
+
+	return nil, nil
+}
+
+func (n WriteFile) Arity() int {
+	return 2
+}
+
+func (n WriteFile) ToString() string {
+	return "<native fn writeFile>"
+}
+
+func (n WriteFile) Bind(instance *LoxInstance) Callable {
+	return n
+}
+
+var _ Callable = (*Exec)(nil)
+
+// Exec runs a command with a list of string arguments, and returns what it wrote to its standard output.
+type Exec struct{}
+
+func (n Exec) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	name, err := stringArgument("exec", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := arguments[1].(*LoxList)
+	if !ok {
+		return nil, fmt.Errorf("Arguments of exec must be a list of strings.")
+	}
+
+	var args []string
+	for _, argument := range list.snapshot() {
+		arg, ok := argument.(string)
+		if !ok {
+			return nil, fmt.Errorf("Arguments of exec must be a list of strings.")
+		}
+		args = append(args, arg)
+	}
+
+	if !interpreter.capabilities.Exec {
+		return nil, permissionDenied("cannot run '%s'", name)
+	}
+
+	cmd := exec.CommandContext(interpreter.budget.ctx, name, args...)
+	cmd.Stderr = interpreter.stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("Command '%s' failed: %v", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Command '%s' failed: %v", name, err)
+	}
+
+	// the output is read while the command runs, so a command that writes too much is stopped.
+	output, err := interpreter.readString(Token{}, stdout)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		if _, ok := err.(*RuntimeError); ok {
+			return nil, err
+		}
+		return nil, fmt.Errorf("Command '%s' failed: %v", name, err)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("Command '%s' failed: %v", name, err)
+	}
+
+	return output, nil
+}
+
+func (n Exec) Arity() int {
+	return 2
+}
+
+func (n Exec) ToString() string {
+	return "<native fn exec>"
+}
+
+func (n Exec) Bind(instance *LoxInstance) Callable {
+	return n
+}
+
+var _ Callable = (*Getenv)(nil)
+
+// Getenv returns the value of an environment variable, or nil when it is not set.
+type Getenv struct{}
+
+func (n Getenv) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	name, err := stringArgument("getenv", arguments[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !interpreter.capabilities.Env {
+		return nil, permissionDenied("cannot read the environment variable '%s'", name)
+	}
+
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, nil
+	}
+
+	return value, nil
+}
+
+func (n Getenv) Arity() int {
+	return 1
+}
+
+func (n Getenv) ToString() string {
+	return "<native fn getenv>"
+}
+
+func (n Getenv) Bind(instance *LoxInstance) Callable {
+	return n
+}
+
+var _ Callable = (*Random)(nil)
+
+// Random returns a random number in [0, 1).
+type Random struct{}
+
+func (n Random) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	if !interpreter.capabilities.Random {
+		return nil, permissionDenied("cannot use random numbers")
+	}
+
+	return rand.Float64(), nil
+}
+
+func (n Random) Arity() int {
+	return 0
+}
+
+func (n Random) ToString() string {
+	return "<native fn random>"
+}
+
+func (n Random) Bind(instance *LoxInstance) Callable {
+	return n
+}
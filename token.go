@@ -30,51 +30,80 @@ const (
 	LESS          TokenType = "LESS"
 	LESS_EQUAL    TokenType = "LESS_EQUAL"
 
+	QUESTION_DOT      TokenType = "QUESTION_DOT"
+	QUESTION_QUESTION TokenType = "QUESTION_QUESTION"
+
 	// 리터럴
 	IDENTIFIER TokenType = "IDENTIFIER"
 	STRING     TokenType = "STRING"
 	NUMBER     TokenType = "NUMBER"
 
 	// 키워드
-	AND    TokenType = "AND"
-	BREAK  TokenType = "BREAK"
-	CLASS  TokenType = "CLASS"
-	ELSE   TokenType = "ELSE"
-	FALSE  TokenType = "FALSE"
-	FUN    TokenType = "FUN"
-	FOR    TokenType = "FOR"
-	IF     TokenType = "IF"
-	NIL    TokenType = "NIL"
-	OR     TokenType = "OR"
-	PRINT  TokenType = "PRINT"
-	RETURN TokenType = "RETURN"
-	SUPER  TokenType = "SUPER"
-	THIS   TokenType = "THIS"
-	TRUE   TokenType = "TRUE"
-	VAR    TokenType = "VAR"
-	WHILE  TokenType = "WHILE"
+	AND     TokenType = "AND"
+	ASYNC   TokenType = "ASYNC"
+	AWAIT   TokenType = "AWAIT"
+	BREAK   TokenType = "BREAK"
+	CASE    TokenType = "CASE"
+	CLASS   TokenType = "CLASS"
+	CONST   TokenType = "CONST"
+	DEFAULT TokenType = "DEFAULT"
+	ELSE    TokenType = "ELSE"
+	ENUM    TokenType = "ENUM"
+	FALSE   TokenType = "FALSE"
+	FUN     TokenType = "FUN"
+	FOR     TokenType = "FOR"
+	IF      TokenType = "IF"
+	IN      TokenType = "IN"
+	MATCH   TokenType = "MATCH"
+	NIL     TokenType = "NIL"
+	OR      TokenType = "OR"
+	PRINT   TokenType = "PRINT"
+	RETURN  TokenType = "RETURN"
+	SELECT  TokenType = "SELECT"
+	SPAWN   TokenType = "SPAWN"
+	SUPER   TokenType = "SUPER"
+	THIS    TokenType = "THIS"
+	TRAIT   TokenType = "TRAIT"
+	TRUE    TokenType = "TRUE"
+	VAR     TokenType = "VAR"
+	WHILE   TokenType = "WHILE"
+	WITH    TokenType = "WITH"
+	YIELD   TokenType = "YIELD"
 
 	EOF TokenType = "EOF"
 )
 
 var KeywordsMap = map[string]TokenType{
-	"and":    AND,
-	"break":  BREAK,
-	"class":  CLASS,
-	"else":   ELSE,
-	"false":  FALSE,
-	"for":    FOR,
-	"fun":    FUN,
-	"if":     IF,
-	"nil":    NIL,
-	"or":     OR,
-	"print":  PRINT,
-	"return": RETURN,
-	"super":  SUPER,
-	"this":   THIS,
-	"true":   TRUE,
-	"var":    VAR,
-	"while":  WHILE,
+	"and":     AND,
+	"async":   ASYNC,
+	"await":   AWAIT,
+	"break":   BREAK,
+	"case":    CASE,
+	"class":   CLASS,
+	"const":   CONST,
+	"default": DEFAULT,
+	"enum":    ENUM,
+	"else":    ELSE,
+	"false":   FALSE,
+	"for":     FOR,
+	"fun":     FUN,
+	"if":      IF,
+	"in":      IN,
+	"match":   MATCH,
+	"nil":     NIL,
+	"or":      OR,
+	"print":   PRINT,
+	"return":  RETURN,
+	"select":  SELECT,
+	"spawn":   SPAWN,
+	"super":   SUPER,
+	"this":    THIS,
+	"trait":   TRAIT,
+	"true":    TRUE,
+	"var":     VAR,
+	"while":   WHILE,
+	"with":    WITH,
+	"yield":   YIELD,
 }
 
 type Token struct {
@@ -0,0 +1,46 @@
+package lox_interpreter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TupleType is an immutable sequence of values, created by `(1, 2)` or `return a, b;`.
+type TupleType []interface{}
+
+func (t TupleType) ToString() string {
+	return Stringify(t)
+}
+
+// tupleKey is the hash key of a tuple. It encodes the type and the value of every element, and prefixes each one
+// with its length, so tuples that are equal have the same key and tuples that are not have different keys.
+type tupleKey string
+
+// hashKey returns the key of tuple, whose elements must be numbers, booleans, strings or tuples of them.
+func hashKey(tuple TupleType) tupleKey {
+	var key strings.Builder
+	writeKey(&key, tuple)
+	return tupleKey(key.String())
+}
+
+func writeKey(key *strings.Builder, value interface{}) {
+	switch value := value.(type) {
+	case float64:
+		// -0 == 0, so both have the key of 0.
+		if value == 0 {
+			value = 0
+		}
+		n := strconv.FormatFloat(value, 'g', -1, 64)
+		fmt.Fprintf(key, "n%d:%s", len(n), n)
+	case bool:
+		fmt.Fprintf(key, "b1:%t", value)
+	case string:
+		fmt.Fprintf(key, "s%d:%s", len(value), value)
+	case TupleType:
+		fmt.Fprintf(key, "t%d:", len(value))
+		for _, element := range value {
+			writeKey(key, element)
+		}
+	}
+}
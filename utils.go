@@ -1,5 +1,7 @@
 package lox_interpreter
 
+import "math"
+
 func isAlphaNumeric(c string) bool {
 	return isAlphabet(c) || isDigit(c)
 }
@@ -11,3 +13,7 @@ func isAlphabet(c string) bool {
 func isDigit(c string) bool {
 	return '0' <= c[0] && c[0] <= '9'
 }
+
+func isInteger(n float64) bool {
+	return n == math.Trunc(n) && !math.IsInf(n, 0)
+}